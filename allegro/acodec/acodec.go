@@ -5,7 +5,7 @@ package acodec
 // #include <allegro5/allegro_acodec.h>
 import "C"
 import (
-	"errors"
+	"github.com/phrasz/nag/allegro"
 )
 
 // TODO: get Allegro to recognize the .oga extension.
@@ -15,7 +15,7 @@ import (
 func Install() error {
 	ok := bool(C.al_init_acodec_addon())
 	if !ok {
-		return errors.New("failed to initialize acodec addon")
+		return &allegro.Error{Op: "install", Addon: "acodec"}
 	}
 	return nil
 }
@@ -0,0 +1,294 @@
+// Package allegrotest helps test rendering code without a display or a GPU.
+// Drawing is done into memory bitmaps, and the result compared with a golden
+// PNG image kept in the package's testdata directory:
+//
+//	func TestHealthBar(t *testing.T) {
+//		allegrotest.Setup(t)
+//		primitives.Install()
+//		allegrotest.Check(t, "health_bar", 64, 8, 1, func() {
+//			drawHealthBar(0, 0, 0.75)
+//		})
+//	}
+//
+// Running the tests with -allegrotest.update writes the golden images instead
+// of checking them. When a check fails, the rendered image is written next to
+// the golden one with a _got suffix, along with a _diff image that marks the
+// pixels that differ in red.
+package allegrotest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+var update = flag.Bool("allegrotest.update", false, "write the golden images of allegrotest instead of checking them")
+
+// setup.owner is the name of the test whose Setup() initialized Allegro.
+var setup struct {
+	sync.Mutex
+	owner string
+}
+
+// Main() runs the tests with Allegro initialized on the calling goroutine, and
+// shuts it down once they are done. It's meant to be called from TestMain(),
+// and lets tests that call Setup() run in parallel:
+//
+//	func TestMain(m *testing.M) {
+//		allegrotest.Main(m)
+//	}
+//
+// The tests run on a goroutine of their own, while the calling goroutine
+// serves as the main thread, running the work that tests send to it with Do()
+// and friends.
+func Main(m *testing.M) {
+	if err := allegro.Init(allegro.NoAtExit()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	queue, err := allegro.CreateEventQueue()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var (
+		code    int
+		running = true
+	)
+	go func() {
+		c := m.Run()
+		allegro.DoAsync(func() { code, running = c, false })
+	}()
+	// Waiting on the queue runs the work queued up with Do(), including the
+	// function that ends the loop.
+	var event allegro.Event
+	for running {
+		queue.WaitForEvent(&event)
+	}
+	queue.Destroy()
+	allegro.Shutdown()
+	os.Exit(code)
+}
+
+// Setup() initializes Allegro for the test, without creating a display, and
+// shuts it down again once the test and its subtests are done. Allegro has to
+// be shut down on the goroutine that initialized it, so it can't be shared
+// with tests running in parallel this way; Setup() fails the test if another
+// one is using it. Such tests should call Main() from TestMain() instead.
+// If Allegro is already running, under Main() or Run(), it is left alone.
+// Addons still need to be installed by the test.
+func Setup(t testing.TB) {
+	t.Helper()
+	setup.Lock()
+	defer setup.Unlock()
+	if setup.owner != "" {
+		if name := t.Name(); name == setup.owner || strings.HasPrefix(name, setup.owner+"/") {
+			return
+		}
+		t.Fatalf("allegrotest: %s is already using Allegro; call allegrotest.Main() from TestMain() to share it between parallel tests", setup.owner)
+	}
+	if allegro.IsSystemInstalled() {
+		return
+	}
+	if err := allegro.Init(allegro.NoAtExit()); err != nil {
+		t.Fatal(err)
+	}
+	setup.owner = t.Name()
+	// Cleanup functions run on the test's own goroutine, after any subtests
+	// are done.
+	t.Cleanup(func() {
+		setup.Lock()
+		defer setup.Unlock()
+		allegro.Shutdown()
+		setup.owner = ""
+	})
+}
+
+// Asserts() records the assertions that fail inside Allegro while the test
+// runs, and reports them as a test error once it is done. Allegro only checks
+// its assertions in debug builds.
+func Asserts(t testing.TB) {
+	t.Helper()
+	r := allegro.RecordAsserts()
+	t.Cleanup(func() {
+		allegro.SetAssertHandler(nil)
+		if err := r.Err(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// Render() calls draw with a new w×h memory bitmap as the target, cleared to
+// transparent black, and returns what was drawn. The bitmap uses a fixed
+// 32-bit RGBA format, and the thread's drawing state is restored afterwards.
+func Render(t testing.TB, w, h int, draw func()) *image.NRGBA {
+	t.Helper()
+	// The target bitmap and the new bitmap parameters are kept per thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	state := allegro.StoreState(allegro.STATE_ALL)
+	allegro.SetNewBitmapFlags(allegro.MEMORY_BITMAP)
+	allegro.SetNewBitmapFormat(allegro.PIXEL_FORMAT_ABGR_8888_LE)
+	bmp := allegro.CreateBitmap(w, h)
+	defer func() {
+		allegro.RestoreState(state)
+		if bmp != nil {
+			bmp.Destroy()
+		}
+	}()
+	if bmp == nil {
+		t.Fatalf("failed to create %dx%d memory bitmap; did you call Setup()?", w, h)
+	}
+	allegro.SetTargetBitmap(bmp)
+	allegro.ClearToColor(allegro.MapRGBA(0, 0, 0, 0))
+	draw()
+
+	img, err := Snapshot(bmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// Snapshot() copies the pixels of a bitmap into an image. The color values are
+// taken as they are stored, so with Allegro's default blender they are
+// premultiplied by alpha.
+func Snapshot(bmp *allegro.Bitmap) (*image.NRGBA, error) {
+	img := image.NewNRGBA(image.Rect(0, 0, bmp.Width(), bmp.Height()))
+	err := bmp.WhileLocked(allegro.PIXEL_FORMAT_ANY, allegro.LOCK_READONLY, func() {
+		for y := 0; y < img.Rect.Dy(); y++ {
+			for x := 0; x < img.Rect.Dx(); x++ {
+				r, g, b, a := bmp.Pixel(x, y).UnmapRGBA()
+				i := img.PixOffset(x, y)
+				img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = r, g, b, a
+			}
+		}
+	})
+	return img, err
+}
+
+// Check() renders draw with Render(), and compares the result with the golden
+// image name; see Golden().
+func Check(t testing.TB, name string, w, h int, tolerance uint8, draw func()) {
+	t.Helper()
+	Golden(t, name, Render(t, w, h, draw), tolerance)
+}
+
+// Golden() compares img with testdata/name.png, allowing each channel of each
+// pixel to be off by up to tolerance, and fails the test if they differ. With
+// -allegrotest.update, it writes img to testdata/name.png instead.
+func Golden(t testing.TB, name string, img image.Image, tolerance uint8) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".png")
+	got := toNRGBA(img)
+	if *update {
+		if err := writePNG(path, got); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("updated %s", path)
+		return
+	}
+
+	gotPath := filepath.Join("testdata", name+"_got.png")
+	want, err := readPNG(path)
+	if err != nil {
+		writePNG(gotPath, got)
+		t.Fatalf("%v; run the test with -allegrotest.update to create it", err)
+	}
+	if want.Rect.Size() != got.Rect.Size() {
+		writePNG(gotPath, got)
+		t.Errorf("%s: image is %v, golden image is %v; wrote %s", name, got.Rect.Size(), want.Rect.Size(), gotPath)
+		return
+	}
+	diff, bad, worst := compare(want, got, tolerance)
+	if bad == 0 {
+		return
+	}
+	diffPath := filepath.Join("testdata", name+"_diff.png")
+	writePNG(gotPath, got)
+	writePNG(diffPath, diff)
+	t.Errorf("%s: %d pixels differ by more than %d, by up to %d; wrote %s and %s",
+		name, bad, tolerance, worst, gotPath, diffPath)
+}
+
+// compare() returns an image that shows the pixels of want, faded, with the
+// ones that got differs from marked in red, along with the number of those and
+// the largest difference in a channel.
+func compare(want, got *image.NRGBA, tolerance uint8) (diff *image.NRGBA, bad, worst int) {
+	size := want.Rect.Size()
+	diff = image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			w := want.Pix[want.PixOffset(want.Rect.Min.X+x, want.Rect.Min.Y+y):][:4]
+			g := got.Pix[got.PixOffset(got.Rect.Min.X+x, got.Rect.Min.Y+y):][:4]
+			most := 0
+			for c := range w {
+				d := int(w[c]) - int(g[c])
+				if d < 0 {
+					d = -d
+				}
+				if d > most {
+					most = d
+				}
+			}
+			if most > worst {
+				worst = most
+			}
+			if most > int(tolerance) {
+				bad++
+				diff.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+			} else {
+				gray := uint8((int(w[0]) + int(w[1]) + int(w[2])) / 3 / 4)
+				diff.SetNRGBA(x, y, color.NRGBA{gray, gray, gray, 255})
+			}
+		}
+	}
+	return diff, bad, worst
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok {
+		return n
+	}
+	b := img.Bounds()
+	n := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(n, n.Rect, img, b.Min, draw.Src)
+	return n
+}
+
+func readPNG(path string) (*image.NRGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return toNRGBA(img), nil
+}
+
+func writePNG(path string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
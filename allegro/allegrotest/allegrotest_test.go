@@ -0,0 +1,91 @@
+package allegrotest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+type resource struct {
+	onMain bool
+}
+
+func (r *resource) Destroy() {
+	r.onMain = allegro.OnMainThread()
+}
+
+func TestScopeCloseUnderMain(t *testing.T) {
+	scope := allegro.NewScope()
+	r := new(resource)
+	scope.Add(r)
+	closed := make(chan struct{})
+	go func() {
+		scope.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Scope.Close() is still waiting for the main thread")
+	}
+	if !r.onMain {
+		t.Error("resource wasn't destroyed on the main thread")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	want := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	got := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	for x := 0; x < 3; x++ {
+		want.SetNRGBA(x, 0, color.NRGBA{100, 100, 100, 255})
+	}
+	got.SetNRGBA(0, 0, color.NRGBA{100, 100, 100, 255})
+	got.SetNRGBA(1, 0, color.NRGBA{102, 99, 100, 255})
+	got.SetNRGBA(2, 0, color.NRGBA{100, 100, 100, 200})
+
+	diff, bad, worst := compare(want, got, 2)
+	if bad != 1 || worst != 55 {
+		t.Errorf("%d pixels differ by up to %d, want 1 by up to 55", bad, worst)
+	}
+	if c := diff.NRGBAAt(2, 0); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("differing pixel is %v in the diff", c)
+	}
+	if c := diff.NRGBAAt(1, 0); c != (color.NRGBA{25, 25, 25, 255}) {
+		t.Errorf("matching pixel is %v in the diff", c)
+	}
+	if _, bad, _ := compare(want, got, 55); bad != 0 {
+		t.Errorf("%d pixels differ with a tolerance of 55", bad)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	Setup(t)
+	Check(t, "pixels", 4, 4, 0, func() {
+		allegro.ClearToColor(allegro.MapRGB(10, 20, 30))
+		allegro.PutPixel(1, 2, allegro.MapRGB(255, 255, 255))
+		allegro.PutPixel(3, 0, allegro.MapRGBA(0, 0, 0, 0))
+	})
+}
+
+func TestSetupSubtests(t *testing.T) {
+	Setup(t)
+	for _, name := range []string{"a", "b"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			Setup(t)
+			img := Render(t, 2, 2, func() {
+				allegro.ClearToColor(allegro.MapRGB(255, 0, 0))
+			})
+			if c := img.NRGBAAt(1, 1); c != (color.NRGBA{255, 0, 0, 255}) {
+				t.Errorf("pixel is %v", c)
+			}
+		})
+	}
+}
@@ -4,18 +4,30 @@ package audio
 
 type AudioStreamFragment interface {
 	audio_stream_fragment()
+	Clone() AudioStreamFragment
 }
 
 type audio_stream_fragment_event struct{} // C.ALLEGRO_EVENT_AUDIO_STREAM_FRAGMENT
 
 func (e *audio_stream_fragment_event) audio_stream_fragment() {}
 
+func (e *audio_stream_fragment_event) Clone() AudioStreamFragment {
+	c := *e
+	return &c
+}
+
 /* -- Audio Stream Finished -- */
 
 type AudioStreamFinished interface {
 	audio_stream_finished()
+	Clone() AudioStreamFinished
 }
 
 type audio_stream_finished_event struct{} // C.ALLEGRO_EVENT_AUDIO_STREAM_FINISHED
 
 func (e *audio_stream_finished_event) audio_stream_finished() {}
+
+func (e *audio_stream_finished_event) Clone() AudioStreamFinished {
+	c := *e
+	return &c
+}
@@ -10,7 +10,16 @@ import (
 	"github.com/phrasz/nag/allegro"
 )
 
+// Allegro destroys all audio objects when the addon is uninstalled.
+var lifetime allegro.Lifetime
+
 func init() {
+	// Instances and streams play into mixers, which play into voices.
+	allegro.RegisterResource((*SampleInstance)(nil), -2, &lifetime)
+	allegro.RegisterResource((*Stream)(nil), -2, &lifetime)
+	allegro.RegisterResource((*Sample)(nil), -1, &lifetime)
+	allegro.RegisterResource((*Mixer)(nil), 1, &lifetime)
+	allegro.RegisterResource((*Voice)(nil), 2, &lifetime)
 	allegro.RegisterEventType(C.ALLEGRO_EVENT_AUDIO_STREAM_FRAGMENT, func(e *allegro.Event) interface{} {
 		return (*audio_stream_fragment_event)(unsafe.Pointer(e))
 	})
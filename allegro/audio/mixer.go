@@ -6,6 +6,8 @@ import "C"
 import (
 	"errors"
 	"fmt"
+
+	"github.com/phrasz/nag/allegro"
 )
 
 type Mixer C.ALLEGRO_MIXER
@@ -23,7 +25,7 @@ const (
 func CreateMixer(freq uint, depth Depth, chan_conf ChannelConf) (*Mixer, error) {
 	mixer := C.al_create_mixer(C.unsigned(freq), C.ALLEGRO_AUDIO_DEPTH(depth), C.ALLEGRO_CHANNEL_CONF(chan_conf))
 	if mixer == nil {
-		return nil, errors.New("failed to create mixer")
+		return nil, &allegro.Error{Op: "create mixer", Addon: "audio"}
 	}
 	return (*Mixer)(mixer), nil
 }
@@ -7,6 +7,7 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"runtime"
 
 	"github.com/phrasz/nag/allegro"
 )
@@ -33,9 +34,12 @@ func CreateSample(samples, freq uint, depth Depth, chan_conf ChannelConf) *Sampl
 func LoadSample(filename string) (*Sample, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !bool(C.al_is_audio_installed()) {
+		return nil, &allegro.Error{Op: "load sample", Path: filename, Addon: "audio", Err: allegro.ErrNotInstalled}
+	}
 	s := C.al_load_sample(filename_)
 	if s == nil {
-		return nil, fmt.Errorf("failed to load sample '%s'", filename)
+		return nil, allegro.LoadError("audio", "load sample", filename)
 	}
 	return (*Sample)(s), nil
 }
@@ -46,10 +50,16 @@ func LoadSample(filename string) (*Sample, error) {
 func LoadSampleF(f *allegro.File, ident string) (*Sample, error) {
 	ident_ := C.CString(ident)
 	defer C.free_string(ident_)
+	if !bool(C.al_is_audio_installed()) {
+		return nil, &allegro.Error{Op: "load sample", Addon: "audio", Err: allegro.ErrNotInstalled}
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	if sample := C.al_load_sample_f((*C.ALLEGRO_FILE)(f), ident_); sample != nil {
 		return (*Sample)(sample), nil
 	}
-	return nil, errors.New("failed to load sample from file")
+	return nil, allegro.LoadFileError("audio", "load sample", "")
 }
 
 // Writes a sample into a file. Currently, wav is the only supported format,
@@ -57,8 +67,11 @@ func LoadSampleF(f *allegro.File, ident string) (*Sample, error) {
 func (s *Sample) Save(filename string) error {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	if !bool(C.al_save_sample(filename_, (*C.ALLEGRO_SAMPLE)(s))) {
-		return fmt.Errorf("failed to save sample to '%s'", filename)
+		return allegro.NewError("audio", "save sample", filename, nil)
 	}
 	return nil
 }
@@ -68,8 +81,11 @@ func (s *Sample) Save(filename string) error {
 func (s *Sample) SaveF(f *allegro.File, ident string) error {
 	ident_ := C.CString(ident)
 	defer C.free_string(ident_)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	if !bool(C.al_save_sample_f((*C.ALLEGRO_FILE)(f), ident_, (*C.ALLEGRO_SAMPLE)(s))) {
-		return errors.New("failed to save sample to file")
+		return allegro.NewError("audio", "save sample", "", nil)
 	}
 	return nil
 }
@@ -269,6 +285,9 @@ func (s *Sample) Destroy() {
 // reserved sample instances are currently used.
 func (s *Sample) Play(gain, pan, speed float32, loop PlayMode) (*SampleID, error) {
 	var id SampleID
+	if !bool(C.al_is_audio_installed()) {
+		return nil, &allegro.Error{Op: "play sample", Addon: "audio", Err: allegro.ErrNotInstalled}
+	}
 	ok := bool(C.al_play_sample(
 		(*C.ALLEGRO_SAMPLE)(s),
 		C.float(gain),
@@ -277,7 +296,7 @@ func (s *Sample) Play(gain, pan, speed float32, loop PlayMode) (*SampleID, error
 		C.ALLEGRO_PLAYMODE(loop),
 		(*C.ALLEGRO_SAMPLE_ID)(&id)))
 	if !ok {
-		return nil, errors.New("failed to play sample")
+		return nil, &allegro.Error{Op: "play sample", Addon: "audio"}
 	}
 	return &id, nil
 }
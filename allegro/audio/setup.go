@@ -5,14 +5,16 @@ package audio
 // #include <allegro5/allegro_audio.h>
 import "C"
 import (
-	"errors"
+	"fmt"
+
+	"github.com/phrasz/nag/allegro"
 )
 
 // Install the audio subsystem.
 func Install() error {
 	ok := bool(C.al_install_audio())
 	if !ok {
-		return errors.New("failed to install audio subsystem")
+		return &allegro.Error{Op: "install", Addon: "audio"}
 	}
 	return nil
 }
@@ -20,6 +22,7 @@ func Install() error {
 // Uninstalls the audio subsystem.
 func Uninstall() {
 	C.al_uninstall_audio()
+	lifetime.End()
 }
 
 // Returns true if al_install_audio was called previously and returned
@@ -35,7 +38,7 @@ func IsAudioInstalled() bool {
 func ReserveSamples(reserve_samples int) error {
 	ok := bool(C.al_reserve_samples(C.int(reserve_samples)))
 	if !ok {
-		return errors.New("failed to reserve audio samples")
+		return &allegro.Error{Op: fmt.Sprintf("reserve %d samples", reserve_samples), Addon: "audio"}
 	}
 	return nil
 }
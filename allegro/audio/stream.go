@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"runtime"
 	"unsafe"
 
 	"github.com/phrasz/nag/allegro"
@@ -43,9 +44,12 @@ func CreateStream(fragment_count, frag_samples, freq uint, depth Depth, chan_con
 func LoadStream(filename string, buffer_count, samples uint) (*Stream, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !bool(C.al_is_audio_installed()) {
+		return nil, &allegro.Error{Op: "load audio stream", Path: filename, Addon: "audio", Err: allegro.ErrNotInstalled}
+	}
 	ptr := C.al_load_audio_stream(filename_, C.size_t(buffer_count), C.unsigned(samples))
 	if ptr == nil {
-		return nil, fmt.Errorf("failed to load audio stream at '%s'", filename)
+		return nil, allegro.LoadError("audio", "load audio stream", filename)
 	}
 	return &Stream{ptr: ptr, buffer_size: 0}, nil
 }
@@ -54,9 +58,15 @@ func LoadStream(filename string, buffer_count, samples uint) (*Stream, error) {
 func LoadStreamF(f *allegro.File, ident string, buffer_count, samples uint) (*Stream, error) {
 	ident_ := C.CString(ident)
 	defer C.free_string(ident_)
+	if !bool(C.al_is_audio_installed()) {
+		return nil, &allegro.Error{Op: "load audio stream", Addon: "audio", Err: allegro.ErrNotInstalled}
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	ptr := C.al_load_audio_stream_f((*C.ALLEGRO_FILE)(f), ident_, C.size_t(buffer_count), C.unsigned(samples))
 	if ptr == nil {
-		return nil, errors.New("failed to load audio stream from file")
+		return nil, allegro.LoadFileError("audio", "load audio stream", "")
 	}
 	return &Stream{ptr: ptr, buffer_size: 0}, nil
 }
@@ -64,9 +74,15 @@ func LoadStreamF(f *allegro.File, ident string, buffer_count, samples uint) (*St
 // TODO: generalize a "Sound" interface that supports audio stream, sample instance, etc.
 
 // Destroy an audio stream which was created with al_create_audio_stream or
-// al_load_audio_stream.
+// al_load_audio_stream. It does nothing if the stream has already been
+// destroyed, so a stream can be passed to allegro.Finalize().
 func (s *Stream) Destroy() {
+	if s.ptr == nil {
+		return
+	}
 	C.al_destroy_audio_stream(s.ptr)
+	s.ptr = nil
+	runtime.SetFinalizer(s, nil)
 }
 
 // Retrieve the associated event source.
@@ -0,0 +1,30 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+
+// This function returns the text that is currently on the clipboard of the
+// display, or an error if the clipboard is empty or holds no text.
+func (d *Display) ClipboardText() (string, error) {
+	text := C.al_get_clipboard_text((*C.ALLEGRO_DISPLAY)(d))
+	if text == nil {
+		return "", &Error{Op: "get clipboard text"}
+	}
+	defer freeString(text)
+	return C.GoString(text), nil
+}
+
+// This function pastes the text given as an argument to the clipboard.
+func (d *Display) SetClipboardText(text string) error {
+	text_ := C.CString(text)
+	defer freeString(text_)
+	if !bool(C.al_set_clipboard_text((*C.ALLEGRO_DISPLAY)(d), text_)) {
+		return &Error{Op: "set clipboard text"}
+	}
+	return nil
+}
+
+// This function returns true if and only if the clipboard has text available.
+func (d *Display) ClipboardHasText() bool {
+	return bool(C.al_clipboard_has_text((*C.ALLEGRO_DISPLAY)(d)))
+}
@@ -5,6 +5,7 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"strconv"
 	"strings"
 )
@@ -27,7 +28,7 @@ func LoadConfig(filename string) (*Config, error) {
 	defer freeString(filename_)
 	cfg := C.al_load_config_file(filename_)
 	if cfg == nil {
-		return nil, fmt.Errorf("failed to load config file '%s'", filename)
+		return nil, LoadError("", "load config", filename)
 	}
 	return (*Config)(cfg), nil
 }
@@ -42,18 +43,24 @@ func MergeConfig(cfg1, cfg2 *Config) *Config {
 
 // Read a configuration file from an already open file.
 func (f *File) LoadConfig() (*Config, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	cfg := C.al_load_config_file_f((*C.ALLEGRO_FILE)(f))
 	if cfg == nil {
-		return nil, errors.New("failed to load config from file")
+		return nil, NewError("", "load config", "", nil)
 	}
 	return (*Config)(cfg), nil
 }
 
 // Write out a configuration file to an already open file.
 func (f *File) SaveConfig(cfg *Config) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	ok := bool(C.al_save_config_file_f((*C.ALLEGRO_FILE)(f), (*C.ALLEGRO_CONFIG)(cfg)))
 	if !ok {
-		return errors.New("failed to save config from file")
+		return NewError("", "save config", "", nil)
 	}
 	return nil
 }
@@ -97,6 +104,16 @@ func (cfg *Config) Value(section, key string) (string, error) {
 	return C.GoString(cvalue), nil
 }
 
+// Remove a key and its associated value in a section of a configuration.
+// Returns true if the entry was removed, or false if the entry did not exist.
+func (cfg *Config) RemoveKey(section, key string) bool {
+	section_ := C.CString(section)
+	key_ := C.CString(key)
+	defer freeString(section_)
+	defer freeString(key_)
+	return bool(C.al_remove_config_key((*C.ALLEGRO_CONFIG)(cfg), section_, key_))
+}
+
 // Add a comment in a section of a configuration. If the section doesn't yet
 // exist, it will be created. The section can be NULL or "" for the global
 // section.
@@ -113,9 +130,12 @@ func (cfg *Config) AddComment(section, comment string) {
 func (cfg *Config) Save(filename string) error {
 	filename_ := C.CString(filename)
 	defer freeString(filename_)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	ok := bool(C.al_save_config_file(filename_, (*C.ALLEGRO_CONFIG)(cfg)))
 	if !ok {
-		return fmt.Errorf("failed to save config file to '%s'", filename)
+		return NewError("", "save config", filename, nil)
 	}
 	return nil
 }
@@ -224,7 +244,7 @@ func (cfg *Config) Entries(section string) <-chan string {
 			err error
 		)
 		e, iter, err = cfg.FirstConfigEntry(section)
-		if err == nil {
+		if err != nil {
 			return
 		}
 		entries <- e
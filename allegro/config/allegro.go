@@ -0,0 +1,43 @@
+//go:build cgo
+// +build cgo
+
+package config
+
+import (
+	"os"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// FromAllegro() converts an Allegro config. Allegro doesn't give access to
+// comments, so the config is saved through Allegro to a temporary file and
+// parsed from there, which keeps them.
+func FromAllegro(cfg *allegro.Config) (*Config, error) {
+	f, err := os.CreateTemp("", "config-*.cfg")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	if err := cfg.Save(f.Name()); err != nil {
+		return nil, err
+	}
+	return Load(f.Name())
+}
+
+// Allegro() converts the configuration to an Allegro config, comments
+// included, which the caller must destroy.
+func (c *Config) Allegro() *allegro.Config {
+	cfg := allegro.CreateConfig()
+	for _, s := range c.sections {
+		cfg.AddSection(s.name)
+		for _, e := range s.entries {
+			if e.comment {
+				cfg.AddComment(s.name, e.key)
+			} else {
+				cfg.SetValue(s.name, e.key, e.value)
+			}
+		}
+	}
+	return cfg
+}
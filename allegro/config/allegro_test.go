@@ -0,0 +1,78 @@
+//go:build cgo
+// +build cgo
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// allegroRoundTrip() loads data with al_load_config_file() and returns what
+// al_save_config_file() writes back out.
+func allegroRoundTrip(t *testing.T, data []byte) []byte {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.cfg"), filepath.Join(dir, "out.cfg")
+	if err := os.WriteFile(in, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := allegro.LoadConfig(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cfg.Destroy()
+	if err := cfg.Save(out); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func FuzzCompatibility(f *testing.F) {
+	f.Add([]byte(testFile))
+	f.Add([]byte("a=1\r\n[s]\r\n  b = 2  \r\n#c\r\n"))
+	f.Add([]byte("[x\n=\n==\n[]]\n]\n#\n\n\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Allegro works with C strings, and its trimming stops at invalid
+		// UTF-8, so neither is worth comparing.
+		if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+			t.Skip()
+		}
+		c, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := c.Bytes(), allegroRoundTrip(t, data); !bytes.Equal(got, want) {
+			t.Errorf("input %q saved as %q, Allegro saves %q", data, got, want)
+		}
+	})
+}
+
+func TestAllegroConversion(t *testing.T) {
+	c, err := Parse(strings.NewReader(testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := c.Allegro()
+	defer cfg.Destroy()
+	if v, err := cfg.Value("graphics", "height"); err != nil || v != "480 = tall" {
+		t.Errorf("graphics.height = '%s' (%v) in the Allegro config", v, err)
+	}
+
+	back, err := FromAllegro(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(back.Bytes()); got != testSaved {
+		t.Errorf("converted back as:\n%s\nwant:\n%s", got, testSaved)
+	}
+}
@@ -0,0 +1,313 @@
+// Package config reads and writes Allegro's configuration files without
+// Allegro, so that tools and tests can use them without initialising it.
+//
+// The dialect is Allegro's own, and files round trip through this package
+// exactly as they do through al_load_config_file() and al_save_config_file():
+//
+//   - Lines are trimmed of whitespace at both ends.
+//   - Blank lines, and lines starting with '#', are comments, and are kept in
+//     place. A '#' anywhere else is part of the key or value.
+//   - "[name]" starts a section; anything after the last ']' is ignored, and
+//     a missing ']' runs to the end of the line. Entries before the first
+//     section header belong to the global section, named "".
+//   - Other lines are "key=value", split at the first '=' and trimmed. A line
+//     without '=' is a key with an empty value.
+//   - A repeated section continues the earlier one, and a repeated key
+//     replaces the earlier value in its original place.
+//   - Saving writes the global section first, then the others in the order
+//     they were created, with entries written as "key=value".
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type entry struct {
+	key, value string
+	comment    bool
+}
+
+type section struct {
+	name    string
+	entries []*entry
+	keys    map[string]*entry
+}
+
+// Config is a configuration file held in memory.
+type Config struct {
+	sections []*section
+	names    map[string]*section
+}
+
+// New() creates an empty configuration, holding only the global section.
+func New() *Config {
+	c := &Config{names: make(map[string]*section)}
+	c.AddSection("")
+	return c
+}
+
+// trim() trims whitespace the same way as al_ustr_trim_ws().
+func trim(s string) string {
+	return strings.Trim(s, " \t\n\v\f\r")
+}
+
+// Parse() reads a configuration file.
+func Parse(r io.Reader) (*Config, error) {
+	c := New()
+	current := ""
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line == "" && err != nil {
+			if err == io.EOF {
+				return c, nil
+			}
+			return nil, err
+		}
+		line = trim(line)
+		switch {
+		case line == "" || line[0] == '#':
+			c.AddComment(current, line)
+		case line[0] == '[':
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				end = len(line)
+			}
+			current = line[1:end]
+			c.AddSection(current)
+		default:
+			key, value := line, ""
+			if eq := strings.IndexByte(line, '='); eq >= 0 {
+				key, value = trim(line[:eq]), trim(line[eq+1:])
+			}
+			c.SetValue(current, key, value)
+		}
+	}
+}
+
+// Load() reads a configuration file from disk.
+func Load(filename string) (*Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file '%s': %v", filename, err)
+	}
+	return c, nil
+}
+
+// WriteTo() writes the configuration out in the same form as
+// al_save_config_file().
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(c.Bytes())
+	return int64(n), err
+}
+
+// Bytes() returns the configuration as it would be written out.
+func (c *Config) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, s := range c.sections {
+		if s.name != "" {
+			buf.WriteString("[" + s.name + "]\n")
+		}
+		for _, e := range s.entries {
+			switch {
+			case !e.comment:
+				buf.WriteString(e.key + "=" + e.value + "\n")
+			case e.key == "":
+				buf.WriteString("\n")
+			case e.key[0] == '#':
+				buf.WriteString(e.key + "\n")
+			default:
+				buf.WriteString("# " + e.key + "\n")
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// Save() writes the configuration to disk.
+func (c *Config) Save(filename string) error {
+	if err := os.WriteFile(filename, c.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to save config file to '%s': %v", filename, err)
+	}
+	return nil
+}
+
+// AddSection() adds a section with the given name. If the section already
+// exists then nothing happens.
+func (c *Config) AddSection(name string) {
+	if _, ok := c.names[name]; ok {
+		return
+	}
+	s := &section{name: name, keys: make(map[string]*entry)}
+	c.sections = append(c.sections, s)
+	c.names[name] = s
+}
+
+func (c *Config) section(name string) *section {
+	c.AddSection(name)
+	return c.names[name]
+}
+
+// SetValue() sets a value in a section, creating the section if it doesn't
+// exist yet. An existing value is replaced in place.
+func (c *Config) SetValue(section, key, value string) {
+	s := c.section(section)
+	if e, ok := s.keys[key]; ok {
+		e.value = value
+		return
+	}
+	e := &entry{key: key, value: value}
+	s.entries = append(s.entries, e)
+	s.keys[key] = e
+}
+
+// Value() returns a value from a section.
+func (c *Config) Value(section, key string) (string, error) {
+	if s, ok := c.names[section]; ok {
+		if e, ok := s.keys[key]; ok {
+			return e.value, nil
+		}
+	}
+	return "", fmt.Errorf("config value '%s.%s' not found", section, key)
+}
+
+// AddComment() adds a comment to the end of a section, creating the section
+// if it doesn't exist yet. Newlines in the comment are replaced by spaces, and
+// it is written with a leading "# " unless it already starts with '#'.
+func (c *Config) AddComment(section, comment string) {
+	comment = strings.ReplaceAll(comment, "\n", " ")
+	s := c.section(section)
+	s.entries = append(s.entries, &entry{key: comment, comment: true})
+}
+
+// RemoveKey() removes a value from a section, and returns false if it didn't
+// exist.
+func (c *Config) RemoveKey(section, key string) bool {
+	s, ok := c.names[section]
+	if !ok {
+		return false
+	}
+	e, ok := s.keys[key]
+	if !ok {
+		return false
+	}
+	delete(s.keys, key)
+	for i := range s.entries {
+		if s.entries[i] == e {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// RemoveSection() removes a section along with its values and comments, and
+// returns false if it didn't exist. The global section is only emptied.
+func (c *Config) RemoveSection(name string) bool {
+	s, ok := c.names[name]
+	if !ok {
+		return false
+	}
+	if name == "" {
+		s.entries, s.keys = nil, make(map[string]*entry)
+		return true
+	}
+	delete(c.names, name)
+	for i := range c.sections {
+		if c.sections[i] == s {
+			c.sections = append(c.sections[:i], c.sections[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Sections() returns the names of the sections, starting with the global
+// section.
+func (c *Config) Sections() []string {
+	names := make([]string, len(c.sections))
+	for i, s := range c.sections {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Entries() returns the keys in a section, in order, leaving out comments.
+func (c *Config) Entries(section string) []string {
+	s, ok := c.names[section]
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, e := range s.entries {
+		if !e.comment {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// Comments() returns the comments in a section, in order. Blank lines are
+// returned as empty comments.
+func (c *Config) Comments(section string) []string {
+	s, ok := c.names[section]
+	if !ok {
+		return nil
+	}
+	var comments []string
+	for _, e := range s.entries {
+		if e.comment {
+			comments = append(comments, e.key)
+		}
+	}
+	return comments
+}
+
+// Merge() merges the values of another configuration into this one,
+// overriding any that exist in both. Comments from add are not kept, as with
+// al_merge_config_into().
+func (c *Config) Merge(add *Config) {
+	for _, s := range add.sections {
+		c.AddSection(s.name)
+		for _, e := range s.entries {
+			if !e.comment {
+				c.SetValue(s.name, e.key, e.value)
+			}
+		}
+	}
+}
+
+// Clone() returns a copy of the configuration, comments included.
+func (c *Config) Clone() *Config {
+	clone := New()
+	for _, s := range c.sections {
+		clone.AddSection(s.name)
+		cs := clone.names[s.name]
+		for _, e := range s.entries {
+			ce := *e
+			cs.entries = append(cs.entries, &ce)
+			if !e.comment {
+				cs.keys[e.key] = &ce
+			}
+		}
+	}
+	return clone
+}
+
+// Merge() returns a new configuration with the values of c2 merged into a copy
+// of c1, like al_merge_config(). Only the comments of c1 are kept.
+func Merge(c1, c2 *Config) *Config {
+	merged := c1.Clone()
+	merged.Merge(c2)
+	return merged
+}
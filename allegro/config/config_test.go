@@ -0,0 +1,102 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFile = `# Shipped defaults.
+  name = Player One
+colour=#ff8000 # not a comment
+
+[graphics]
+width=640
+fullscreen
+height = 480 = tall
+width=800
+
+[audio
+volume=1
+[graphics] trailing text
+vsync=true
+[]
+late=global
+`
+
+const testSaved = `# Shipped defaults.
+name=Player One
+colour=#ff8000 # not a comment
+
+late=global
+[graphics]
+width=800
+fullscreen=
+height=480 = tall
+
+vsync=true
+[audio]
+volume=1
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse(strings.NewReader(testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []struct{ section, key, want string }{
+		{"", "name", "Player One"},
+		{"", "colour", "#ff8000 # not a comment"},
+		{"", "late", "global"},
+		{"graphics", "width", "800"},
+		{"graphics", "fullscreen", ""},
+		{"graphics", "height", "480 = tall"},
+		{"graphics", "vsync", "true"},
+		{"audio", "volume", "1"},
+	} {
+		if got, err := c.Value(v.section, v.key); err != nil || got != v.want {
+			t.Errorf("%s.%s = '%s' (%v), want '%s'", v.section, v.key, got, err, v.want)
+		}
+	}
+	if got, want := c.Sections(), []string{"", "graphics", "audio"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sections are %q, want %q", got, want)
+	}
+	if got, want := c.Entries("graphics"), []string{"width", "fullscreen", "height", "vsync"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("graphics entries are %q, want %q", got, want)
+	}
+	if got := string(c.Bytes()); got != testSaved {
+		t.Errorf("saved as:\n%s\nwant:\n%s", got, testSaved)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	c := New()
+	c.AddComment("", "Written by a tool\nacross two lines")
+	c.SetValue("a", "x", "1")
+	c.AddComment("a", "# already marked")
+	c.SetValue("b", "y", "2")
+	c.SetValue("a", "z", "3")
+	if !c.RemoveKey("a", "x") || c.RemoveKey("a", "x") {
+		t.Error("RemoveKey() didn't remove the key exactly once")
+	}
+	if !c.RemoveSection("b") || c.RemoveSection("b") {
+		t.Error("RemoveSection() didn't remove the section exactly once")
+	}
+	want := "# Written by a tool across two lines\n[a]\n# already marked\nz=3\n"
+	if got := string(c.Bytes()); got != want {
+		t.Errorf("saved as %q, want %q", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	c1, _ := Parse(strings.NewReader("# one\na=1\n[s]\nb=2\n"))
+	c2, _ := Parse(strings.NewReader("# two\n[t]\nc=3\n[s]\nb=4\n"))
+	merged := Merge(c1, c2)
+	want := "# one\na=1\n[s]\nb=4\n[t]\nc=3\n"
+	if got := string(merged.Bytes()); got != want {
+		t.Errorf("merged into %q, want %q", got, want)
+	}
+	if v, _ := c1.Value("s", "b"); v != "2" {
+		t.Error("Merge() changed its first argument")
+	}
+}
@@ -0,0 +1,284 @@
+package allegro
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Struct tags name the section and key each field is stored under, like so:
+//
+//	type Settings struct {
+//		Fullscreen bool          `ini:"graphics.fullscreen"`
+//		Volume     float32       `ini:"audio.volume"`
+//		Name       string        `ini:"name"`
+//		Jump       KeyCode       `ini:"controls.jump"`
+//		Audio      AudioSettings `ini:"audio"`
+//		Scratch    int           `ini:"-"`
+//	}
+//
+// A tag without a section puts the key in the global section, or in the
+// section of the struct it belongs to. Fields of struct type are sections of
+// their own, named by their tag, and a field with no tag uses its own name as
+// the key. Sections may contain dots; the key is whatever follows the last
+// one.
+
+var (
+	colorType    = reflect.TypeOf(Color{})
+	keyCodeType  = reflect.TypeOf(KeyCode(0))
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// configKey() returns how a key is referred to in errors.
+func configKey(section, key string) string {
+	if section == "" {
+		return key
+	}
+	return section + "." + key
+}
+
+// walkConfigFields() calls fn for every field of the struct v that is stored
+// in a config, along with the section and key it is stored under.
+func walkConfigFields(v reflect.Value, section string, fn func(section, key string, field reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Tag.Get("ini")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if f.Type.Kind() == reflect.Struct && f.Type != colorType {
+			if section != "" && !strings.Contains(name, ".") {
+				name = section + "." + name
+			}
+			if err := walkConfigFields(v.Field(i), name, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		s, key := section, name
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			s, key = name[:dot], name[dot+1:]
+		}
+		if err := fn(s, key, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unmarshal() fills in the struct that v points to from the config, as
+// described by its fields' ini tags. Fields whose keys aren't in the config
+// are left as they are, so they can be given defaults beforehand.
+func (cfg *Config) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal config into %T, need a pointer to a struct", v)
+	}
+	return walkConfigFields(rv.Elem(), "", func(section, key string, field reflect.Value) error {
+		str, err := cfg.Value(section, key)
+		if err != nil {
+			return nil
+		}
+		if err := parseConfigValue(strings.TrimSpace(str), field); err != nil {
+			return fmt.Errorf("config value '%s': %v", configKey(section, key), err)
+		}
+		return nil
+	})
+}
+
+// Marshal() stores the fields of the struct v, or the struct it points to, in
+// the config, as described by their ini tags.
+func (cfg *Config) Marshal(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot marshal %T into a config, need a struct", v)
+	}
+	return walkConfigFields(rv, "", func(section, key string, field reflect.Value) error {
+		str, err := formatConfigValue(field)
+		if err != nil {
+			return fmt.Errorf("config value '%s': %v", configKey(section, key), err)
+		}
+		cfg.SetValue(section, key, str)
+		return nil
+	})
+}
+
+// MarshalConfig() creates a config holding the fields of the struct v. It
+// panics if v isn't a struct, or has fields of types that can't be stored;
+// use Config.Marshal() to get an error instead.
+func MarshalConfig(v interface{}) *Config {
+	cfg := CreateConfig()
+	if err := cfg.Marshal(v); err != nil {
+		cfg.Destroy()
+		panic(err)
+	}
+	return cfg
+}
+
+/* -- Values -- */
+
+// Keys are stored by their StableName(), or by number if they have none.
+func formatKeyCode(k KeyCode) string {
+	if name := k.StableName(); name != "" {
+		return name
+	}
+	return strconv.Itoa(int(k))
+}
+
+func parseKeyCode(str string) (KeyCode, error) {
+	if k, ok := KeyCodeByName(str); ok {
+		return k, nil
+	}
+	if n, err := strconv.Atoi(str); err == nil {
+		return KeyCode(n), nil
+	}
+	return 0, fmt.Errorf("unknown key '%s'", str)
+}
+
+// Colors are written as #rrggbb, or #rrggbbaa if they aren't opaque. The
+// floating point variants are used since they work before Allegro is
+// initialised.
+func formatColor(c Color) string {
+	r, g, b, a := c.UnmapRGBAf()
+	round := func(f float32) int {
+		return int(math.Round(float64(f) * 255))
+	}
+	if round(a) == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", round(r), round(g), round(b))
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", round(r), round(g), round(b), round(a))
+}
+
+func parseColor(str string) (Color, error) {
+	hex := strings.TrimPrefix(str, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 || !strings.HasPrefix(str, "#") {
+		return Color{}, fmt.Errorf("invalid color '%s', need #rrggbb or #rrggbbaa", str)
+	}
+	return MapRGBAf(float32(n>>24)/255, float32(n>>16&0xff)/255,
+		float32(n>>8&0xff)/255, float32(n&0xff)/255), nil
+}
+
+func formatConfigValue(v reflect.Value) (string, error) {
+	switch v.Type() {
+	case colorType:
+		return formatColor(v.Interface().(Color)), nil
+	case keyCodeType:
+		return formatKeyCode(KeyCode(v.Int())), nil
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Slice {
+			break
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			item, err := formatConfigValue(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return strings.Join(items, ", "), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func parseConfigValue(str string, v reflect.Value) error {
+	switch v.Type() {
+	case colorType:
+		c, err := parseColor(str)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(c))
+		return nil
+	case keyCodeType:
+		k, err := parseKeyCode(str)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(k))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(strings.TrimSpace(stripComment(str)))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(stripComment(str)))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(stripComment(str)), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(stripComment(str)), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(stripComment(str)), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(str)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Slice {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var items []string
+		if str != "" {
+			items = strings.Split(str, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := parseConfigValue(strings.TrimSpace(item), slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package allegro
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAudio struct {
+	Volume float32 `ini:"volume"`
+	Muted  bool    `ini:"muted"`
+	Mixer  struct {
+		Rate uint `ini:"rate"`
+	} `ini:"mixer"`
+}
+
+type testSettings struct {
+	Name       string        `ini:"name"`
+	Fullscreen bool          `ini:"graphics.fullscreen"`
+	Width      int           `ini:"graphics.width"`
+	Scale      float64       `ini:"graphics.scale"`
+	Background Color         `ini:"graphics.background"`
+	Delay      time.Duration `ini:"input.repeat_delay"`
+	Jump       KeyCode       `ini:"input.jump"`
+	Levels     []string      `ini:"game.levels"`
+	Scores     []int         `ini:"game.scores"`
+	Audio      testAudio     `ini:"audio"`
+	Untagged   int
+	Skipped    int `ini:"-"`
+	hidden     int
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	in := testSettings{
+		Name:       "Player One",
+		Fullscreen: true,
+		Width:      1280,
+		Scale:      1.5,
+		Background: MapRGBAf(1, 128.0/255, 0, 1),
+		Delay:      250 * time.Millisecond,
+		Jump:       KEY_SPACE,
+		Levels:     []string{"intro", "caves", "castle"},
+		Scores:     []int{10, -20, 30},
+		Untagged:   7,
+		Skipped:    9,
+		hidden:     3,
+	}
+	in.Audio.Volume = 0.75
+	in.Audio.Mixer.Rate = 44100
+
+	cfg := MarshalConfig(in)
+	defer cfg.Destroy()
+	for _, c := range []struct{ section, key, want string }{
+		{"", "name", "Player One"},
+		{"graphics", "background", "#ff8000"},
+		{"input", "repeat_delay", "250ms"},
+		{"input", "jump", "SPACE"},
+		{"game", "levels", "intro, caves, castle"},
+		{"audio", "volume", "0.75"},
+		{"audio.mixer", "rate", "44100"},
+		{"", "Untagged", "7"},
+	} {
+		if got, err := cfg.Value(c.section, c.key); err != nil || got != c.want {
+			t.Errorf("%s: '%s' (%v), want '%s'", configKey(c.section, c.key), got, err, c.want)
+		}
+	}
+	if _, err := cfg.Value("", "Skipped"); err == nil {
+		t.Error("field tagged '-' was stored")
+	}
+
+	out := testSettings{Skipped: 1}
+	if err := cfg.Unmarshal(&out); err != nil {
+		t.Fatal(err)
+	}
+	in.Skipped, in.hidden = 1, 0
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip gave %+v, want %+v", out, in)
+	}
+}
+
+func TestConfigUnmarshalDefaults(t *testing.T) {
+	cfg := CreateConfig()
+	defer cfg.Destroy()
+	cfg.SetValue("graphics", "width", "800 # pixels")
+	cfg.SetValue("graphics", "background", "#10203080")
+	cfg.SetValue("game", "levels", "")
+
+	out := testSettings{Name: "default", Levels: []string{"x"}}
+	if err := cfg.Unmarshal(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "default" || out.Width != 800 || len(out.Levels) != 0 {
+		t.Errorf("unexpected settings %+v", out)
+	}
+	if c := formatColor(out.Background); c != "#10203080" {
+		t.Errorf("background is %s, want #10203080", c)
+	}
+}
+
+func TestConfigUnmarshalErrors(t *testing.T) {
+	for _, c := range []struct{ section, key, value string }{
+		{"graphics", "width", "wide"},
+		{"graphics", "fullscreen", "sometimes"},
+		{"graphics", "background", "red"},
+		{"input", "repeat_delay", "10 parsecs"},
+		{"game", "scores", "1, two"},
+		{"audio.mixer", "rate", "-1"},
+	} {
+		cfg := CreateConfig()
+		cfg.SetValue(c.section, c.key, c.value)
+		var out testSettings
+		err := cfg.Unmarshal(&out)
+		if err == nil || !strings.Contains(err.Error(), c.section+"."+c.key) {
+			t.Errorf("%s = %s: got error %v, want one naming the key", c.section, c.key, err)
+		}
+		cfg.Destroy()
+	}
+
+	cfg := CreateConfig()
+	defer cfg.Destroy()
+	if err := cfg.Unmarshal(testSettings{}); err == nil {
+		t.Error("unmarshalled into a struct that isn't a pointer")
+	}
+	if err := cfg.Marshal(struct{ C chan int }{}); err == nil {
+		t.Error("marshalled a channel")
+	}
+}
+
+func TestConfigEntries(t *testing.T) {
+	cfg := CreateConfig()
+	defer cfg.Destroy()
+	cfg.SetValue("s", "a", "1")
+	cfg.SetValue("s", "b", "2")
+	var keys []string
+	for e := range cfg.Entries("s") {
+		keys = append(keys, e)
+	}
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("entries are %v, want [a b]", keys)
+	}
+	for range cfg.Entries("missing") {
+		t.Error("entries listed for a missing section")
+	}
+}
@@ -0,0 +1,317 @@
+package allegro
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ConfigStack layers configs on top of each other, such as a game's shipped
+// defaults under the player's own settings. Values in higher layers override
+// those in lower ones, and changes are made to the top layer, so that it only
+// ever holds what differs from the layers below.
+//
+//	stack := allegro.NewConfigStack()
+//	defer stack.Destroy()
+//	if err := stack.PushFile("defaults", "defaults.cfg"); err != nil {
+//		return err
+//	}
+//	if err := stack.PushUserFile("user", "settings.cfg"); err != nil {
+//		return err
+//	}
+//	stack.OnChange(func(section, key string) {
+//		log.Printf("%s.%s changed", section, key)
+//	})
+//	stack.SetValue("graphics", "fullscreen", "true")
+//	err := stack.Save()
+type ConfigStack struct {
+	layers    []*configLayer
+	merged    *Config
+	callbacks []func(section, key string)
+}
+
+type configLayer struct {
+	name     string
+	cfg      *Config
+	filename string
+	modTime  time.Time
+}
+
+// NewConfigStack() creates an empty stack.
+func NewConfigStack() *ConfigStack {
+	return &ConfigStack{merged: CreateConfig()}
+}
+
+// Push() adds a layer on top of the stack. The stack takes ownership of the
+// config, and destroys it along with itself.
+func (s *ConfigStack) Push(name string, cfg *Config) {
+	s.layers = append(s.layers, &configLayer{name: name, cfg: cfg})
+	s.Refresh()
+}
+
+// PushFile() loads a config file and adds it on top of the stack. A file that
+// doesn't exist yet is treated as empty, and is created when the stack is
+// saved.
+func (s *ConfigStack) PushFile(name, filename string) error {
+	layer := &configLayer{name: name, filename: filename}
+	if err := layer.load(); err != nil {
+		return err
+	}
+	s.layers = append(s.layers, layer)
+	s.Refresh()
+	return nil
+}
+
+// PushUserFile() is like PushFile(), but looks for the file in the user
+// settings directory, which depends on the organization and application names.
+func (s *ConfigStack) PushUserFile(name, filename string) error {
+	dir, err := GetStandardPath(USER_SETTINGS_PATH)
+	if err != nil {
+		return err
+	}
+	return s.PushFile(name, filepath.Join(dir, filename))
+}
+
+func (l *configLayer) load() error {
+	info, err := os.Stat(l.filename)
+	if os.IsNotExist(err) {
+		l.cfg, l.modTime = CreateConfig(), time.Time{}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(l.filename)
+	if err != nil {
+		return err
+	}
+	l.cfg, l.modTime = cfg, info.ModTime()
+	return nil
+}
+
+// Layer() returns the config of the named layer, or nil if there's no such
+// layer. Changes made to it directly aren't seen by the stack until Refresh()
+// is called.
+func (s *ConfigStack) Layer(name string) *Config {
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if s.layers[i].name == name {
+			return s.layers[i].cfg
+		}
+	}
+	return nil
+}
+
+// Top() returns the config of the top layer, or nil if the stack is empty.
+func (s *ConfigStack) Top() *Config {
+	if len(s.layers) == 0 {
+		return nil
+	}
+	return s.layers[len(s.layers)-1].cfg
+}
+
+// Merged() returns the merged view of every layer. It belongs to the stack,
+// and is replaced whenever the stack changes. Comments are kept from the
+// bottom layer, so defaults can document the settings.
+func (s *ConfigStack) Merged() *Config {
+	return s.merged
+}
+
+// Value() returns a value from the merged view.
+func (s *ConfigStack) Value(section, key string) (string, error) {
+	return s.merged.Value(section, key)
+}
+
+// Origin() returns the name of the layer that a value comes from, and false
+// if no layer has it.
+func (s *ConfigStack) Origin(section, key string) (string, bool) {
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if _, err := s.layers[i].cfg.Value(section, key); err == nil {
+			return s.layers[i].name, true
+		}
+	}
+	return "", false
+}
+
+// SetValue() sets a value in the top layer. If the value is the same as the
+// one the layers below would give, it is removed from the top layer instead.
+func (s *ConfigStack) SetValue(section, key, value string) {
+	top := s.Top()
+	if top == nil {
+		return
+	}
+	if below, ok := s.valueBelowTop(section, key); ok && below == value {
+		top.RemoveKey(section, key)
+	} else {
+		top.SetValue(section, key, value)
+	}
+	s.Refresh()
+}
+
+// RemoveKey() removes a value from the top layer, so that the one from the
+// layers below shows through.
+func (s *ConfigStack) RemoveKey(section, key string) {
+	if top := s.Top(); top != nil && top.RemoveKey(section, key) {
+		s.Refresh()
+	}
+}
+
+func (s *ConfigStack) valueBelowTop(section, key string) (string, bool) {
+	for i := len(s.layers) - 2; i >= 0; i-- {
+		if value, err := s.layers[i].cfg.Value(section, key); err == nil {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Sections() returns a read-only channel of sections in the merged view.
+func (s *ConfigStack) Sections() <-chan string {
+	return s.merged.Sections()
+}
+
+// Entries() returns a read-only channel of entries in a section of the merged
+// view.
+func (s *ConfigStack) Entries(section string) <-chan string {
+	return s.merged.Entries(section)
+}
+
+// Unmarshal() fills in a struct from the merged view; see
+// Config.Unmarshal().
+func (s *ConfigStack) Unmarshal(v interface{}) error {
+	return s.merged.Unmarshal(v)
+}
+
+// OnChange() registers a callback to be called for every value of the merged
+// view that changes, whether through SetValue(), RemoveKey(), Refresh() or a
+// file being edited outside of the game.
+func (s *ConfigStack) OnChange(f func(section, key string)) {
+	s.callbacks = append(s.callbacks, f)
+}
+
+// Refresh() rebuilds the merged view, after layers have been changed
+// directly, and calls the change callbacks for anything that differs.
+func (s *ConfigStack) Refresh() {
+	before := configValues(s.merged)
+	s.rebuild()
+	after := configValues(s.merged)
+	for k, v := range after {
+		if old, ok := before[k]; !ok || old != v {
+			s.changed(k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			s.changed(k)
+		}
+	}
+}
+
+func (s *ConfigStack) changed(k configEntry) {
+	for _, f := range s.callbacks {
+		f(k.section, k.key)
+	}
+}
+
+// Poll() checks whether any of the files behind the layers have been changed
+// since they were loaded or saved, and reloads them if so. It is meant to be
+// called now and then, from a timer for instance, to pick up edits made
+// outside of the game. A layer that fails to reload keeps its old values, and
+// the errors are returned together once the other layers have been checked.
+func (s *ConfigStack) Poll() error {
+	var errs []error
+	reloaded := false
+	for _, l := range s.layers {
+		if l.filename == "" {
+			continue
+		}
+		info, err := os.Stat(l.filename)
+		if err != nil || info.ModTime().Equal(l.modTime) {
+			continue
+		}
+		old := l.cfg
+		if err := l.load(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		old.Destroy()
+		reloaded = true
+	}
+	if reloaded {
+		s.Refresh()
+	}
+	return errors.Join(errs...)
+}
+
+// Save() writes the top layer back to the file it was loaded from. Only
+// values that differ from the lower layers are written.
+func (s *ConfigStack) Save() error {
+	if len(s.layers) == 0 {
+		return nil
+	}
+	top := s.layers[len(s.layers)-1]
+	if top.filename == "" {
+		return fmt.Errorf("config layer '%s' has no file to save to", top.name)
+	}
+	if err := os.MkdirAll(filepath.Dir(top.filename), 0755); err != nil {
+		return err
+	}
+	if err := top.cfg.Save(top.filename); err != nil {
+		return err
+	}
+	if info, err := os.Stat(top.filename); err == nil {
+		top.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// Destroy() destroys every layer and the merged view.
+func (s *ConfigStack) Destroy() {
+	for _, l := range s.layers {
+		l.cfg.Destroy()
+	}
+	s.layers = nil
+	s.merged.Destroy()
+	s.merged = nil
+}
+
+// rebuild() merges the layers from the bottom up. MergeConfig() keeps the
+// comments of its first argument, so the bottom layer is merged with the
+// next rather than into an empty config.
+func (s *ConfigStack) rebuild() {
+	var merged *Config
+	switch len(s.layers) {
+	case 0:
+		merged = CreateConfig()
+	case 1:
+		empty := CreateConfig()
+		merged = MergeConfig(s.layers[0].cfg, empty)
+		empty.Destroy()
+	default:
+		merged = MergeConfig(s.layers[0].cfg, s.layers[1].cfg)
+		for _, l := range s.layers[2:] {
+			merged.Merge(l.cfg)
+		}
+	}
+	if s.merged != nil {
+		s.merged.Destroy()
+	}
+	s.merged = merged
+}
+
+type configEntry struct {
+	section, key string
+}
+
+// configValues() reads every value in a config.
+func configValues(cfg *Config) map[configEntry]string {
+	values := make(map[configEntry]string)
+	for section := range cfg.Sections() {
+		for key := range cfg.Entries(section) {
+			if value, err := cfg.Value(section, key); err == nil {
+				values[configEntry{section, key}] = value
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,211 @@
+package allegro
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStack() *ConfigStack {
+	defaults := CreateConfig()
+	defaults.AddComment("graphics", "Settings for the display.")
+	defaults.SetValue("graphics", "width", "640")
+	defaults.SetValue("graphics", "fullscreen", "false")
+	defaults.SetValue("audio", "volume", "1")
+
+	user := CreateConfig()
+	user.SetValue("graphics", "width", "1280")
+
+	stack := NewConfigStack()
+	stack.Push("defaults", defaults)
+	stack.Push("user", user)
+	return stack
+}
+
+func TestConfigStackOrigin(t *testing.T) {
+	stack := newTestStack()
+	defer stack.Destroy()
+
+	for _, c := range []struct{ section, key, value, origin string }{
+		{"graphics", "width", "1280", "user"},
+		{"graphics", "fullscreen", "false", "defaults"},
+		{"audio", "volume", "1", "defaults"},
+	} {
+		if v, _ := stack.Value(c.section, c.key); v != c.value {
+			t.Errorf("%s.%s = '%s', want '%s'", c.section, c.key, v, c.value)
+		}
+		if o, _ := stack.Origin(c.section, c.key); o != c.origin {
+			t.Errorf("%s.%s comes from '%s', want '%s'", c.section, c.key, o, c.origin)
+		}
+	}
+	if _, ok := stack.Origin("graphics", "missing"); ok {
+		t.Error("found an origin for a missing key")
+	}
+
+	var sections []string
+	for s := range stack.Sections() {
+		sections = append(sections, s)
+	}
+	if want := []string{"", "graphics", "audio"}; !reflect.DeepEqual(sections, want) {
+		t.Errorf("sections are %q, want %q", sections, want)
+	}
+}
+
+func TestConfigStackChanges(t *testing.T) {
+	stack := newTestStack()
+	defer stack.Destroy()
+
+	var changes []string
+	stack.OnChange(func(section, key string) {
+		changes = append(changes, section+"."+key)
+	})
+
+	stack.SetValue("audio", "volume", "0.5")
+	stack.SetValue("graphics", "width", "1280")
+	if want := []string{"audio.volume"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes are %v, want %v", changes, want)
+	}
+
+	// Setting a value back to its default takes it out of the top layer.
+	changes = nil
+	stack.SetValue("graphics", "width", "640")
+	if _, err := stack.Top().Value("graphics", "width"); err == nil {
+		t.Error("default value was kept in the top layer")
+	}
+	if o, _ := stack.Origin("graphics", "width"); o != "defaults" {
+		t.Errorf("width comes from '%s', want 'defaults'", o)
+	}
+
+	stack.Layer("defaults").SetValue("audio", "muted", "true")
+	stack.Layer("defaults").SetValue("audio", "volume", "0.8")
+	stack.Refresh()
+	sort.Strings(changes)
+	if want := []string{"audio.muted", "graphics.width"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes are %v, want %v", changes, want)
+	}
+
+	changes = nil
+	stack.RemoveKey("audio", "volume")
+	if v, _ := stack.Value("audio", "volume"); v != "0.8" || len(changes) != 1 {
+		t.Errorf("volume is '%s' after %v, want '0.8'", v, changes)
+	}
+}
+
+func TestConfigStackSaveWithoutFile(t *testing.T) {
+	stack := newTestStack()
+	defer stack.Destroy()
+	if err := stack.Save(); err == nil {
+		t.Error("saved a layer that has no file")
+	}
+}
+
+func TestConfigStackKeepsComments(t *testing.T) {
+	stack := newTestStack()
+	defer stack.Destroy()
+	stack.Layer("defaults").AddComment("controls", "Key bindings go here.")
+	// Both of these rebuild the merged view.
+	stack.Refresh()
+	stack.SetValue("graphics", "fullscreen", "true")
+
+	var sections []string
+	for s := range stack.Sections() {
+		sections = append(sections, s)
+	}
+	if want := []string{"", "graphics", "audio", "controls"}; !reflect.DeepEqual(sections, want) {
+		t.Errorf("sections are %q, want %q", sections, want)
+	}
+	var keys []string
+	for k := range stack.Entries("graphics") {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if want := []string{"fullscreen", "width"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("graphics entries are %q, want %q", keys, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "merged.cfg")
+	if err := stack.Merged().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comment := range []string{"Settings for the display.", "Key bindings go here."} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("comment '%s' is missing from the merged view:\n%s", comment, data)
+		}
+	}
+}
+
+func TestConfigStackPoll(t *testing.T) {
+	dir := t.TempDir()
+	defaults := filepath.Join(dir, "defaults.cfg")
+	user := filepath.Join(dir, "user.cfg")
+	if err := os.WriteFile(defaults, []byte("[graphics]\nwidth=640\nfullscreen=false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(user, []byte("[graphics]\nwidth=1280\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stack := NewConfigStack()
+	defer stack.Destroy()
+	if err := stack.PushFile("defaults", defaults); err != nil {
+		t.Fatal(err)
+	}
+	if err := stack.PushFile("user", user); err != nil {
+		t.Fatal(err)
+	}
+	var changes []string
+	stack.OnChange(func(section, key string) {
+		changes = append(changes, section+"."+key)
+	})
+
+	if err := stack.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes %v without any edits", changes)
+	}
+
+	// Edit the user's file as if from outside the game, making sure that its
+	// modification time moves on even on coarse filesystems.
+	if err := os.WriteFile(user, []byte("[graphics]\nfullscreen=true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(user, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := stack.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(changes)
+	if want := []string{"graphics.fullscreen", "graphics.width"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes are %v, want %v", changes, want)
+	}
+	for _, c := range []struct{ key, value, origin string }{
+		{"width", "640", "defaults"},
+		{"fullscreen", "true", "user"},
+	} {
+		if v, _ := stack.Value("graphics", c.key); v != c.value {
+			t.Errorf("graphics.%s = '%s', want '%s'", c.key, v, c.value)
+		}
+		if o, _ := stack.Origin("graphics", c.key); o != c.origin {
+			t.Errorf("graphics.%s comes from '%s', want '%s'", c.key, o, c.origin)
+		}
+	}
+
+	changes = nil
+	if err := stack.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes %v after the file was already reloaded", changes)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build allegrodebug
+// +build allegrodebug
+
+package allegro
+
+// #include <stdint.h>
+import "C"
+import (
+	"fmt"
+	"sync"
+)
+
+// With the allegrodebug build tag, every display remembers the thread that
+// created it, and the thread-affine functions panic when called from any
+// other thread. This catches the kind of misuse that otherwise shows up as
+// silently missing draws or driver crashes.
+
+var displayThreads = struct {
+	sync.Mutex
+	m map[*Display]C.uintptr_t
+}{m: make(map[*Display]C.uintptr_t)}
+
+func trackDisplay(d *Display) {
+	displayThreads.Lock()
+	displayThreads.m[d] = currentThread()
+	displayThreads.Unlock()
+}
+
+func untrackDisplay(d *Display) {
+	displayThreads.Lock()
+	delete(displayThreads.m, d)
+	displayThreads.Unlock()
+}
+
+func checkDisplayThread(op string, d *Display) {
+	displayThreads.Lock()
+	owner, ok := displayThreads.m[d]
+	displayThreads.Unlock()
+	if ok && owner != currentThread() {
+		panic(fmt.Sprintf("allegro: %s called from a thread that doesn't own the display; use allegro.Do()", op))
+	}
+}
+
+func checkCurrentDisplay(op string) {
+	if CurrentDisplay() == nil {
+		panic(fmt.Sprintf("allegro: %s called from a thread with no current display; use allegro.Do()", op))
+	}
+}
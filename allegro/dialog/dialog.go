@@ -62,7 +62,7 @@ const (
 // Initialise the native dialog addon.
 func Install() error {
 	if !bool(C.al_init_native_dialog_addon()) {
-		return errors.New("failed to initialize native dialog addon!")
+		return &allegro.Error{Op: "install", Addon: "dialog"}
 	}
 	return nil
 }
@@ -94,7 +94,7 @@ func CreateNativeFileDialog(initial_path, title, patterns string, flags FileChoo
 	defer C.free_string(patterns_)
 	d := C.al_create_native_file_dialog(initial_path_, title_, patterns_, C.int(flags))
 	if d == nil {
-		return nil, errors.New("failed to create native file chooser dialog")
+		return nil, &allegro.Error{Op: "create file chooser", Addon: "dialog"}
 	}
 	dialog := (*FileChooser)(d)
 	//runtime.SetFinalizer(dialog, dialog.Destroy)
@@ -154,7 +154,7 @@ func OpenNativeTextLog(title string, flags TextLogFlags) (*TextLog, error) {
 	defer C.free_string(title_)
 	l := C.al_open_native_text_log(title_, C.int(flags))
 	if l == nil {
-		return nil, errors.New("failed to open native text log")
+		return nil, &allegro.Error{Op: "open text log", Addon: "dialog"}
 	}
 	log := (*TextLog)(l)
 	return log, nil
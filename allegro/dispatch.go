@@ -0,0 +1,259 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+// #include <stdint.h>
+// #include <stdlib.h>
+/*
+enum { NAG_DISPATCH_EVENT = ALLEGRO_GET_EVENT_TYPE('N', 'A', 'G', 'D') };
+
+static _Thread_local char thread_marker;
+
+// The address of a thread-local variable is unique to each running thread,
+// which makes it a cheap stand-in for a thread id.
+static uintptr_t current_thread(void) {
+	return (uintptr_t)&thread_marker;
+}
+
+static ALLEGRO_EVENT_SOURCE *create_dispatch_source(void) {
+	ALLEGRO_EVENT_SOURCE *src = calloc(1, sizeof(ALLEGRO_EVENT_SOURCE));
+	al_init_user_event_source(src);
+	return src;
+}
+
+static void destroy_dispatch_source(ALLEGRO_EVENT_SOURCE *src) {
+	al_destroy_user_event_source(src);
+	free(src);
+}
+
+static bool emit_dispatch_event(ALLEGRO_EVENT_SOURCE *src) {
+	ALLEGRO_EVENT ev;
+	ev.user.type = NAG_DISPATCH_EVENT;
+	return al_emit_user_event(src, &ev, NULL);
+}
+*/
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// The dispatcher lets other goroutines hand work over to the thread running
+// the function passed to Run(). Work is queued up on the Go side, and a user
+// event is emitted to wake up whichever event queue the main loop is waiting
+// on. Every event queue created with CreateEventQueue() is registered with
+// the dispatcher's event source, so the queued work gets run as soon as the
+// main loop pulls that event out of its queue.
+var dispatcher struct {
+	sync.Mutex
+	source     *C.ALLEGRO_EVENT_SOURCE
+	mainThread C.uintptr_t
+	pending    []func()
+
+	// Each call to Run() is a new session, so that resources left over from
+	// an earlier one are never passed back to Allegro; see Scope.
+	session, sessions int
+}
+
+func init() {
+	RegisterEventType(C.NAG_DISPATCH_EVENT, func(e *Event) interface{} {
+		return (*dispatch_event)(unsafe.Pointer(e))
+	})
+}
+
+// newWakeSource() creates a private user event source whose only purpose is
+// to wake up an event queue it has been registered with, via wake().
+func newWakeSource() *C.ALLEGRO_EVENT_SOURCE {
+	return C.create_dispatch_source()
+}
+
+func wake(src *C.ALLEGRO_EVENT_SOURCE) {
+	C.emit_dispatch_event(src)
+}
+
+// registerWakeSource() registers src with the queue directly, keeping it out
+// of the list returned by EventQueue.Sources().
+func registerWakeSource(queue *EventQueue, src *C.ALLEGRO_EVENT_SOURCE) {
+	C.al_register_event_source((*C.ALLEGRO_EVENT_QUEUE)(queue), src)
+}
+
+func destroyWakeSource(src *C.ALLEGRO_EVENT_SOURCE) {
+	C.destroy_dispatch_source(src)
+}
+
+func startDispatcher() {
+	dispatcher.Lock()
+	defer dispatcher.Unlock()
+	dispatcher.mainThread = C.current_thread()
+	dispatcher.source = newWakeSource()
+	dispatcher.sessions++
+	dispatcher.session = dispatcher.sessions
+}
+
+// stopDispatcher() is called on the main thread while Allegro is still
+// installed. Work that was queued up before it is still run, so that nobody is
+// left waiting in Do(); work queued up after it is run directly by Do().
+func stopDispatcher() {
+	dispatcher.Lock()
+	src := dispatcher.source
+	pending := dispatcher.pending
+	dispatcher.source = nil
+	dispatcher.pending = nil
+	dispatcher.Unlock()
+
+	for _, f := range pending {
+		f()
+	}
+
+	dispatcher.Lock()
+	if src != nil {
+		destroyWakeSource(src)
+	}
+	dispatcher.mainThread = 0
+	dispatcher.session = 0
+	dispatcher.Unlock()
+}
+
+// currentSession() returns the session Allegro is running, or 0 if it isn't.
+func currentSession() int {
+	dispatcher.Lock()
+	defer dispatcher.Unlock()
+	return dispatcher.session
+}
+
+func currentThread() C.uintptr_t {
+	return C.current_thread()
+}
+
+// OnMainThread() returns true if it is called from the thread that Allegro
+// was started on, i.e. from within the function passed to Run().
+func OnMainThread() bool {
+	dispatcher.Lock()
+	main := dispatcher.mainThread
+	dispatcher.Unlock()
+	return main != 0 && currentThread() == main
+}
+
+// post queues up f to be run on the main thread, and wakes up the main loop.
+// It returns false if Allegro isn't running.
+func post(f func()) bool {
+	dispatcher.Lock()
+	src := dispatcher.source
+	if src == nil {
+		dispatcher.Unlock()
+		return false
+	}
+	dispatcher.pending = append(dispatcher.pending, f)
+	dispatcher.Unlock()
+	wake(src)
+	return true
+}
+
+// RunPending() runs any work that has been queued up with Do(), DoAsync() or
+// DoErr(). It is called automatically whenever an event queue created with
+// CreateEventQueue() returns the dispatcher's wake-up event, so it only needs
+// to be called by main loops that don't wait on an event queue. Calling it
+// from anywhere but the main thread does nothing.
+func RunPending() {
+	if !OnMainThread() {
+		return
+	}
+	for {
+		dispatcher.Lock()
+		pending := dispatcher.pending
+		dispatcher.pending = nil
+		dispatcher.Unlock()
+		if len(pending) == 0 {
+			return
+		}
+		for _, f := range pending {
+			f()
+		}
+	}
+}
+
+// Do() runs f on the main thread and waits for it to return. Functions that
+// are tied to the thread owning a display (see the package documentation)
+// can be safely called from any goroutine this way. If f panics, the panic is
+// passed on to the caller of Do().
+//
+// When called from the main thread itself, or when Allegro isn't running, f
+// is simply called directly.
+func Do(f func()) {
+	if OnMainThread() {
+		f()
+		return
+	}
+	var (
+		done = make(chan struct{})
+		p    interface{}
+	)
+	ok := post(func() {
+		defer close(done)
+		defer func() { p = recover() }()
+		f()
+	})
+	if !ok {
+		f()
+		return
+	}
+	<-done
+	if p != nil {
+		panic(p)
+	}
+}
+
+// DoAsync() queues up f to be run on the main thread, and returns without
+// waiting for it.
+func DoAsync(f func()) {
+	if !post(f) {
+		f()
+	}
+}
+
+// DoErr() is like Do(), but passes on the error returned by f.
+func DoErr(f func() error) error {
+	var err error
+	Do(func() { err = f() })
+	return err
+}
+
+/* -- Dispatch -- */
+
+// DispatchEvent is returned for the events used to wake up the main loop
+// after work has been queued with Do(). When the event is read on the main
+// thread the work has already been run by the time it is returned, so it can
+// simply be ignored. Code that reads events on another goroutine and passes
+// them on to the main thread, like EventQueue.Events(), should have the main
+// thread call RunPending() when it gets one.
+type DispatchEvent interface {
+	dispatch()
+	Clone() DispatchEvent
+}
+
+type dispatch_event C.struct_ALLEGRO_USER_EVENT
+
+func (e *dispatch_event) dispatch() {}
+
+// received() converts an event that has just been taken out of a queue, and
+// runs any queued work if it is the dispatcher's wake-up event.
+func (e *Event) received() interface{} {
+	e.receive()
+	return e.cast()
+}
+
+// receive() is received() without the conversion.
+func (e *Event) receive() {
+	e.unwrap(true)
+	if (*dispatch_event)(unsafe.Pointer(e))._type == C.NAG_DISPATCH_EVENT {
+		RunPending()
+	}
+}
+
+func (e *dispatch_event) Clone() DispatchEvent {
+	c := *e
+	return &c
+}
+
+func (e *dispatch_event) Source() *EventSource {
+	return (*EventSource)(e.source)
+}
@@ -0,0 +1,45 @@
+package allegro
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoAcrossShutdown(t *testing.T) {
+	if err := Init(NoAtExit()); err != nil {
+		t.Fatal(err)
+	}
+	onMain := make(chan bool, 1)
+	returned := make(chan struct{})
+	go func() {
+		Do(func() { onMain <- OnMainThread() })
+		close(returned)
+	}()
+
+	// Nothing runs the queued work until Shutdown().
+	for {
+		dispatcher.Lock()
+		n := len(dispatcher.pending)
+		dispatcher.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	Shutdown()
+
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do() is still blocked after Shutdown()")
+	}
+	if !<-onMain {
+		t.Error("work queued before Shutdown() didn't run on the main thread")
+	}
+
+	var ran bool
+	Do(func() { ran = true })
+	if !ran {
+		t.Error("Do() didn't run its work after Shutdown()")
+	}
+}
@@ -3,8 +3,8 @@ package allegro
 // #include <allegro5/allegro.h>
 import "C"
 import (
-	"errors"
 	"fmt"
+	"runtime"
 	"unsafe"
 )
 
@@ -88,11 +88,18 @@ const (
 // display will automatically make it the active one, with the backbuffer
 // selected for drawing.
 func CreateDisplay(w, h int) (*Display, error) {
+	if !bool(C.al_is_system_installed()) {
+		return nil, &Error{Op: "create display", Err: ErrNotInstalled}
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	d := C.al_create_display(C.int(w), C.int(h))
 	if d == nil {
-		return nil, errors.New("failed to create display!")
+		return nil, NewError("", "create display", "", nil)
 	}
 	display := (*Display)(d)
+	trackDisplay(display)
 	//runtime.SetFinalizer(display, func(d_ *Display) { d_.Destroy() })
 	return display, nil
 }
@@ -102,6 +109,7 @@ func CreateDisplay(w, h int) (*Display, error) {
 // Pointers to the special back buffer bitmap remain valid and retain their
 // semantics as the back buffer, although the contents may have changed.
 func FlipDisplay() {
+	checkCurrentDisplay("FlipDisplay")
 	C.al_flip_display()
 }
 
@@ -109,6 +117,7 @@ func FlipDisplay() {
 // region. With many drivers this is not possible, but for some it can improve
 // performance.
 func UpdateDisplayRegion(x, y, width, height int) {
+	checkCurrentDisplay("UpdateDisplayRegion")
 	C.al_update_display_region(C.int(x), C.int(y), C.int(width), C.int(height))
 }
 
@@ -192,7 +201,7 @@ func ResetDisplayFlags() {
 func InhibitScreensaver(inhibit bool) error {
 	success := bool(C.al_inhibit_screensaver(C.bool(inhibit)))
 	if !success {
-		return errors.New("failed to inhibit screensaver!")
+		return &Error{Op: "inhibit screensaver"}
 	}
 	return nil
 }
@@ -202,7 +211,7 @@ func InhibitScreensaver(inhibit bool) error {
 func WaitForVSync() error {
 	success := bool(C.al_wait_for_vsync())
 	if !success {
-		return errors.New("cannot wait for vsync!")
+		return &Error{Op: "wait for vsync"}
 	}
 	return nil
 }
@@ -216,7 +225,7 @@ func GetDisplayMode(index int) (*DisplayMode, error) {
 	var mode C.struct_ALLEGRO_DISPLAY_MODE
 	result := C.al_get_display_mode(C.int(index), &mode)
 	if result == nil {
-		return nil, fmt.Errorf("error getting display mode '%d'", index)
+		return nil, &Error{Op: fmt.Sprintf("get display mode %d", index)}
 	}
 	return (*DisplayMode)(&mode), nil
 }
@@ -254,6 +263,8 @@ func NumDisplayModes() int {
 
 // Destroy a display.
 func (d *Display) Destroy() {
+	checkDisplayThread("Display.Destroy", d)
+	untrackDisplay(d)
 	C.al_destroy_display((*C.ALLEGRO_DISPLAY)(d))
 }
 
@@ -261,9 +272,10 @@ func (d *Display) Destroy() {
 // al_set_new_display_flags. The only flags that can be changed after creation
 // are:
 func (d *Display) SetDisplayFlag(flags DisplayFlags, onoff bool) error {
+	checkDisplayThread("Display.SetDisplayFlag", d)
 	success := bool(C.al_set_display_flag((*C.ALLEGRO_DISPLAY)(d), C.int(flags), C.bool(onoff)))
 	if !success {
-		return errors.New("failed to set display flag!")
+		return &Error{Op: "set display flag"}
 	}
 	return nil
 }
@@ -309,6 +321,7 @@ func (d *Display) Height() int {
 // the display to be resized they must call this function to let the graphics
 // driver know that it can now resize the display. Returns true on success.
 func (d *Display) AcknowledgeResize() bool {
+	checkDisplayThread("Display.AcknowledgeResize", d)
 	return bool(C.al_acknowledge_resize((*C.ALLEGRO_DISPLAY)(d)))
 }
 
@@ -328,9 +341,10 @@ func (d *Display) Backbuffer() *Bitmap {
 // on both fullscreen and windowed displays, regardless of the
 // ALLEGRO_RESIZABLE flag.
 func (d *Display) Resize(width, height int) error {
+	checkDisplayThread("Display.Resize", d)
 	success := bool(C.al_resize_display((*C.ALLEGRO_DISPLAY)(d), C.int(width), C.int(height)))
 	if !success {
-		return errors.New("failed to resize display!")
+		return &Error{Op: fmt.Sprintf("resize display to %dx%d", width, height)}
 	}
 	return nil
 }
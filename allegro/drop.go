@@ -0,0 +1,94 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// Display flag that makes the display accept files and text dropped onto it,
+// which arrive as DropEvents.
+const DRAG_AND_DROP DisplayFlags = C.ALLEGRO_DRAG_AND_DROP
+
+func init() {
+	RegisterEventType(C.ALLEGRO_EVENT_DROP, func(e *Event) interface{} {
+		return (*drop_event)(unsafe.Pointer(e))
+	})
+}
+
+/* -- Drop -- */
+
+// DropEvent is generated when a file or a piece of text is dropped onto a
+// display created with the DRAG_AND_DROP flag. Dropping several files at once
+// generates one event per file, numbered by Row(), with IsComplete() set on
+// the last one.
+//
+// The text of the event is allocated by Allegro, so Free() must be called
+// once the event has been handled. Clones share the text of the original.
+type DropEvent interface {
+	drop()
+	Clone() DropEvent
+	Timestamp() float64
+	Source() *Display
+	X() int
+	Y() int
+	Row() int
+	IsFile() bool
+	Text() string
+	IsComplete() bool
+	Free()
+}
+
+type drop_event C.struct_ALLEGRO_DROP_EVENT
+
+func (e *drop_event) drop() {}
+
+func (e *drop_event) Clone() DropEvent {
+	c := *e
+	return &c
+}
+
+func (e *drop_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *drop_event) Source() *Display {
+	return (*Display)(e.source)
+}
+
+func (e *drop_event) X() int {
+	return int(e.x)
+}
+
+func (e *drop_event) Y() int {
+	return int(e.y)
+}
+
+func (e *drop_event) Row() int {
+	return int(e.row)
+}
+
+// IsFile() returns true if the text of the event is the path of a dropped
+// file, and false if it is dropped text.
+func (e *drop_event) IsFile() bool {
+	return bool(e.is_file)
+}
+
+func (e *drop_event) Text() string {
+	if e.text == nil {
+		return ""
+	}
+	return C.GoString(e.text)
+}
+
+func (e *drop_event) IsComplete() bool {
+	return bool(e.is_complete)
+}
+
+func (e *drop_event) Free() {
+	if e.text != nil {
+		freeString(e.text)
+		e.text = nil
+	}
+}
@@ -0,0 +1,164 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"errors"
+	"runtime"
+	"syscall"
+)
+
+// Sentinel errors, for use with errors.Is(). An *Error matches one of these
+// when it is the cause of the failure.
+var (
+	// The subsystem or addon the call relies on hasn't been installed.
+	ErrNotInstalled = errors.New("not installed")
+
+	// The file doesn't exist. Errors with an Errno of ENOENT also match this.
+	ErrNotFound = errors.New("not found")
+
+	// The file exists, but none of the registered loaders or savers could
+	// handle it. Either there is none for its extension (is the addon that
+	// handles it installed?), or the data is corrupt.
+	ErrUnsupportedFormat = errors.New("unsupported format")
+)
+
+// Error describes a failed call into Allegro. Op is what was being done, in
+// lower case ("load bitmap"), Path is the file it was done to, if any, and
+// Addon names the addon responsible, or is empty for the core library. Errno
+// is Allegro's errno for the calling thread after the failure, which is zero
+// when Allegro didn't set it. Err is one of the sentinel errors, or nil.
+//
+// Installing a subsystem or addon, creating or opening something, loading or
+// saving a file, reading and writing files, playing samples, and the calls on
+// displays, the clipboard and haptic devices all return an *Error when they
+// fail. Some calls that change the state of something that already exists,
+// such as setting a mixer's gain, still return plain errors.
+type Error struct {
+	Op    string
+	Path  string
+	Addon string
+	Errno syscall.Errno
+	Err   error
+}
+
+func (e *Error) Error() string {
+	s := "allegro"
+	if e.Addon != "" {
+		s += "/" + e.Addon
+	}
+	if e.Op != "" {
+		s += ": " + e.Op
+	}
+	if e.Path != "" {
+		s += " '" + e.Path + "'"
+	}
+	switch {
+	case e.Err != nil && e.Errno != 0 && e.Err != ErrNotFound:
+		return s + ": " + e.Err.Error() + " (" + e.Errno.Error() + ")"
+	case e.Err != nil:
+		return s + ": " + e.Err.Error()
+	case e.Errno != 0:
+		return s + ": " + e.Errno.Error()
+	}
+	return s + ": failed"
+}
+
+// Unwrap() returns Err and Errno, so that errors.Is() also matches standard
+// errors such as fs.ErrNotExist and fs.ErrPermission.
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	if e.Errno != 0 {
+		errs = append(errs, e.Errno)
+	}
+	return errs
+}
+
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.Errno == syscall.ENOENT
+}
+
+// NewError() returns an error for a failed call, with the errno that Allegro
+// set for the calling thread. Allegro doesn't clear errno when calls succeed,
+// so callers should do that before the call that failed. Since errno is kept
+// per thread, the goroutine has to stay locked to its thread, with
+// runtime.LockOSThread(), from clearing errno until NewError() is called. It
+// is exported for the addon packages.
+func NewError(addon, op, path string, err error) *Error {
+	return &Error{
+		Op:    op,
+		Path:  path,
+		Addon: addon,
+		Errno: syscall.Errno(C.al_get_errno()),
+		Err:   err,
+	}
+}
+
+// LoadError() returns an error for a loader that failed to read path.
+// Allegro's loaders don't say why they failed, so the file is opened again
+// through the current file interface to find out: if that fails too, the
+// error has its errno and, if the file doesn't exist, ErrNotFound. Otherwise
+// the file couldn't be understood, and the error is ErrUnsupportedFormat.
+// Addons should check that they're installed before loading anything, and
+// return ErrNotInstalled themselves. It is exported for the addon packages.
+func LoadError(addon, op, path string) *Error {
+	e := &Error{Op: op, Path: path, Addon: addon}
+	if !bool(C.al_is_system_installed()) {
+		e.Err = ErrNotInstalled
+		return e
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	path_ := C.CString(path)
+	mode_ := C.CString("rb")
+	defer freeString(path_)
+	defer freeString(mode_)
+	C.al_set_errno(0)
+	f := C.al_fopen(path_, mode_)
+	if f != nil {
+		C.al_fclose(f)
+		e.Err = ErrUnsupportedFormat
+		return e
+	}
+	e.Errno = syscall.Errno(C.al_get_errno())
+	if e.Errno == 0 && !bool(C.al_filename_exists(path_)) {
+		e.Errno = syscall.ENOENT
+	}
+	if e.Errno == syscall.ENOENT {
+		e.Err = ErrNotFound
+	}
+	return e
+}
+
+// LoadFileError() returns an error for a loader that failed to read from an
+// open file, with path being the name it was given, if any. If reading the
+// file set errno, the error has it; otherwise the data couldn't be
+// understood, and the error is ErrUnsupportedFormat. Like NewError(), it must
+// be called on the thread that cleared errno before loading. It is exported
+// for the addon packages.
+func LoadFileError(addon, op, path string) *Error {
+	e := NewError(addon, op, path, nil)
+	if e.Errno == 0 {
+		e.Err = ErrUnsupportedFormat
+	}
+	return e
+}
+
+// Some Allegro functions will set an error number as well as returning an
+// error code. Call this function to retrieve the last error number set for the
+// calling thread.
+func LastError() error {
+	return &Error{Errno: syscall.Errno(C.al_get_errno())}
+}
+
+// Set the error number for for the calling thread. Passing nil clears it.
+func SetLastError(e *Error) {
+	var errno syscall.Errno
+	if e != nil {
+		errno = e.Errno
+	}
+	C.al_set_errno(C.int(errno))
+}
@@ -0,0 +1,60 @@
+package allegro
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	missing := &Error{Op: "load bitmap", Path: "a.png", Errno: syscall.ENOENT, Err: ErrNotFound}
+	denied := &Error{Op: "load config", Path: "b.cfg", Errno: syscall.EACCES}
+	for _, c := range []struct {
+		err    error
+		target error
+		want   bool
+	}{
+		{missing, ErrNotFound, true},
+		{missing, fs.ErrNotExist, true},
+		{missing, ErrUnsupportedFormat, false},
+		{&Error{Errno: syscall.ENOENT}, ErrNotFound, true},
+		{denied, fs.ErrPermission, true},
+		{denied, ErrNotFound, false},
+		{&Error{Op: "get keyboard event source", Err: ErrNotInstalled}, ErrNotInstalled, true},
+		{&Error{Addon: "audio", Err: ErrUnsupportedFormat}, ErrUnsupportedFormat, true},
+	} {
+		if got := errors.Is(c.err, c.target); got != c.want {
+			t.Errorf("errors.Is(%q, %q) = %v", c.err, c.target, got)
+		}
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	for _, c := range []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{Op: "load bitmap", Path: "a.png", Errno: syscall.ENOENT, Err: ErrNotFound},
+			"allegro: load bitmap 'a.png': not found"},
+		{&Error{Op: "load sample", Path: "b.ogg", Addon: "audio", Err: ErrUnsupportedFormat},
+			"allegro/audio: load sample 'b.ogg': unsupported format"},
+		{&Error{Op: "save config", Path: "c.cfg", Errno: syscall.EACCES},
+			"allegro: save config 'c.cfg': " + syscall.EACCES.Error()},
+		{&Error{Op: "create display"}, "allegro: create display: failed"},
+	} {
+		if got := c.err.Error(); got != c.want {
+			t.Errorf("error is %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestLoadErrorNotInstalled(t *testing.T) {
+	if IsSystemInstalled() {
+		t.Skip("allegro is installed")
+	}
+	err := LoadError("", "load bitmap", "missing.png")
+	if !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("error is %q, want ErrNotInstalled", err)
+	}
+}
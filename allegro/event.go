@@ -5,6 +5,8 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"runtime/cgo"
+	"sync"
 	"unsafe"
 )
 
@@ -16,10 +18,27 @@ type EventSource C.ALLEGRO_EVENT_SOURCE
 
 type EventQueue C.ALLEGRO_EVENT_QUEUE
 
+// queueSources keeps track of the sources registered with each queue, since
+// Allegro has no way of listing them. Sources that Allegro unregisters behind
+// our back, such as destroyed displays, are weeded out by Sources().
+var queueSources = struct {
+	sync.Mutex
+	m map[*EventQueue][]*EventSource
+}{m: make(map[*EventQueue][]*EventSource)}
+
+// sourceData holds the handles of the values attached to event sources with
+// SetData().
+var sourceData = struct {
+	sync.Mutex
+	m map[*EventSource]cgo.Handle
+}{m: make(map[*EventSource]cgo.Handle)}
+
 // Initialise an event source for emitting user events. The space for the event
-// source must already have been allocated.
-func (source EventSource) InitUserEventSource() {
-	C.al_init_user_event_source((*C.ALLEGRO_EVENT_SOURCE)(&source))
+// source must already have been allocated. Allegro holds on to the source
+// once it is registered with a queue, so it must not live in Go memory; use
+// NewUserEventSource() unless the source was allocated in C.
+func (source *EventSource) InitUserEventSource() {
+	C.al_init_user_event_source((*C.ALLEGRO_EVENT_SOURCE)(source))
 }
 
 // Emit a user event. The event source must have been initialised with
@@ -54,29 +73,58 @@ func (source *EventSource) EmitUserEvent(data ...uintptr) error {
 
 // Destroy an event source initialised with al_init_user_event_source.
 func (source *EventSource) DestroyUserEventSource() {
+	source.SetData(nil)
 	C.al_destroy_user_event_source((*C.ALLEGRO_EVENT_SOURCE)(source))
 }
 
 // Assign the abstract user data to the event source. Allegro does not use the
 // data internally for anything; it is simply meant as a convenient way to
 // associate your own data or objects with events.
-func (source *EventSource) SetData(data uintptr) {
-	C.al_set_event_source_data((*C.ALLEGRO_EVENT_SOURCE)(source), C.intptr_t(data))
+//
+// The value is kept alive until it is replaced, so sources that aren't user
+// event sources should have their data set to nil before they are destroyed.
+func (source *EventSource) SetData(data interface{}) {
+	sourceData.Lock()
+	defer sourceData.Unlock()
+	if h, ok := sourceData.m[source]; ok {
+		h.Delete()
+		delete(sourceData.m, source)
+	}
+	if data == nil {
+		C.al_set_event_source_data((*C.ALLEGRO_EVENT_SOURCE)(source), 0)
+		return
+	}
+	h := cgo.NewHandle(data)
+	sourceData.m[source] = h
+	C.al_set_event_source_data((*C.ALLEGRO_EVENT_SOURCE)(source), C.intptr_t(h))
 }
 
 // Returns the abstract user data associated with the event source. If no data
-// was previously set, returns NULL.
-func (source *EventSource) Data() uintptr {
-	return uintptr(C.al_get_event_source_data((*C.ALLEGRO_EVENT_SOURCE)(source)))
+// was previously set, returns nil.
+func (source *EventSource) Data() interface{} {
+	sourceData.Lock()
+	defer sourceData.Unlock()
+	if h, ok := sourceData.m[source]; ok {
+		return h.Value()
+	}
+	return nil
 }
 
 // Create a new, empty event queue, returning a pointer to object if
 // successful. Returns NULL on error.
+//
+// The queue is automatically registered with the event source used by Do()
+// and friends to wake up the main loop.
 func CreateEventQueue() (*EventQueue, error) {
 	q := C.al_create_event_queue()
 	if q == nil {
-		return nil, errors.New("failed to create event queue!")
+		return nil, &Error{Op: "create event queue"}
+	}
+	dispatcher.Lock()
+	if dispatcher.source != nil {
+		registerWakeSource((*EventQueue)(q), dispatcher.source)
 	}
+	dispatcher.Unlock()
 	return (*EventQueue)(q), nil
 }
 
@@ -85,6 +133,9 @@ func CreateEventQueue() (*EventQueue, error) {
 // destroyed.
 func (queue *EventQueue) Destroy() {
 	C.al_destroy_event_queue((*C.ALLEGRO_EVENT_QUEUE)(queue))
+	queueSources.Lock()
+	delete(queueSources.m, queue)
+	queueSources.Unlock()
 }
 
 // Shorthand method for registering anything with an EventSource() method.
@@ -100,6 +151,14 @@ func (queue *EventQueue) Register(obs ...EventGenerator) {
 // does nothing.
 func (queue *EventQueue) RegisterEventSource(source *EventSource) {
 	C.al_register_event_source((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT_SOURCE)(source))
+	queueSources.Lock()
+	defer queueSources.Unlock()
+	for _, s := range queueSources.m[queue] {
+		if s == source {
+			return
+		}
+	}
+	queueSources.m[queue] = append(queueSources.m[queue], source)
 }
 
 // Shorthand method for registering anything with an EventSource() method.
@@ -111,6 +170,57 @@ func (queue *EventQueue) Unregister(ob EventGenerator) {
 // actually registered with the event queue, nothing happens.
 func (queue *EventQueue) UnregisterEventSource(source *EventSource) {
 	C.al_unregister_event_source((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT_SOURCE)(source))
+	queueSources.Lock()
+	defer queueSources.Unlock()
+	sources := queueSources.m[queue]
+	for i, s := range sources {
+		if s == source {
+			queueSources.m[queue] = append(sources[:i], sources[i+1:]...)
+			break
+		}
+	}
+}
+
+// Shorthand method for checking anything with an EventSource() method.
+func (queue *EventQueue) IsRegistered(ob EventGenerator) bool {
+	return queue.IsEventSourceRegistered(ob.EventSource())
+}
+
+// Return true if the event source is registered.
+func (queue *EventQueue) IsEventSourceRegistered(source *EventSource) bool {
+	return bool(C.al_is_event_source_registered((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT_SOURCE)(source)))
+}
+
+// Sources() returns the event sources currently registered with the queue, in
+// the order they were registered. Sources registered internally, such as the
+// one used by Do(), aren't included.
+func (queue *EventQueue) Sources() []*EventSource {
+	queueSources.Lock()
+	defer queueSources.Unlock()
+	var sources []*EventSource
+	for _, s := range queueSources.m[queue] {
+		if queue.IsEventSourceRegistered(s) {
+			sources = append(sources, s)
+		}
+	}
+	queueSources.m[queue] = sources
+	return append([]*EventSource(nil), sources...)
+}
+
+// Pause or resume accepting new events into the event queue (to resume, pass
+// false for pause). Events already in the queue are unaffected.
+//
+// While a queue is paused, any events which would be entered into the queue
+// are simply ignored. This is an alternative to unregistering then
+// re-registering all event sources from the event queue, if you just need to
+// prevent events piling up in the queue for a while.
+func (queue *EventQueue) Pause(pause bool) {
+	C.al_pause_event_queue((*C.ALLEGRO_EVENT_QUEUE)(queue), C.bool(pause))
+}
+
+// Returns true if the event queue is paused.
+func (queue *EventQueue) IsPaused() bool {
+	return bool(C.al_is_event_queue_paused((*C.ALLEGRO_EVENT_QUEUE)(queue)))
 }
 
 // Return true if the event queue specified is currently empty.
@@ -126,6 +236,7 @@ func (queue *EventQueue) PeekNextEvent(event *Event) (interface{}, error) {
 	if ok := bool(C.al_peek_next_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))); !ok {
 		return nil, EmptyQueue
 	}
+	event.unwrap(false)
 	return event.cast(), nil
 }
 
@@ -148,7 +259,7 @@ func (queue *EventQueue) GetNextEvent(event *Event) (interface{}, error) {
 	if ok := bool(C.al_get_next_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))); !ok {
 		return nil, EmptyQueue
 	}
-	return event.cast(), nil
+	return event.received(), nil
 }
 
 // Wait until the event queue specified is non-empty. If ret_event is not NULL,
@@ -160,7 +271,7 @@ func (queue *EventQueue) WaitForEvent(event *Event) interface{} {
 	if event == nil {
 		return nil
 	}
-	return event.cast()
+	return event.received()
 }
 
 // Wait until the event queue specified is non-empty. If ret_event is not NULL,
@@ -174,7 +285,7 @@ func (queue *EventQueue) WaitForEventTimed(event *Event, secs float32) (interfac
 	if event == nil {
 		return nil, true
 	}
-	return event.cast(), true
+	return event.received(), true
 }
 
 // Wait until the event queue specified is non-empty. If ret_event is not NULL,
@@ -188,11 +299,28 @@ func (queue *EventQueue) WaitForEventUntil(timeout *Timeout, event *Event) (inte
 	if event == nil {
 		return nil, true
 	}
-	return event.cast(), true
+	return event.received(), true
+}
+
+// WaitForEventInto() waits until the event queue is non-empty, and moves the
+// first event into event, which must not be nil. Unlike WaitForEvent(), it
+// doesn't make a typed view of the event, so it doesn't allocate. Reading
+// each event into an Event of its own, such as the next slot of a buffer used
+// to record input, keeps them stable without cloning; Event.Typed() gives the
+// typed view of one later on.
+func (queue *EventQueue) WaitForEventInto(event *Event) {
+	C.al_wait_for_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))
+	event.receive()
 }
 
 type Event C.union_ALLEGRO_EVENT
 
+// Typed() returns the typed view of an event read with WaitForEventInto(),
+// the same as the one returned by WaitForEvent().
+func (e *Event) Typed() interface{} {
+	return e.cast()
+}
+
 // RegisterEventType() lets modules register their own event types.
 func RegisterEventType(t C.ALLEGRO_EVENT_TYPE, f func(*Event) interface{}) {
 	registeredEvents[t] = f
@@ -229,6 +357,15 @@ func (e *Event) cast() interface{} {
 	case C.ALLEGRO_EVENT_MOUSE_LEAVE_DISPLAY:
 		return (*mouse_leave_display_event)(unsafe.Pointer(e))
 
+	case C.ALLEGRO_EVENT_TOUCH_BEGIN:
+		return (*touch_begin_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_TOUCH_MOVE:
+		return (*touch_move_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_TOUCH_END:
+		return (*touch_end_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_TOUCH_CANCEL:
+		return (*touch_cancel_event)(unsafe.Pointer(e))
+
 	case C.ALLEGRO_EVENT_TIMER:
 		return (*timer_event)(unsafe.Pointer(e))
 
@@ -258,10 +395,16 @@ func (e *Event) cast() interface{} {
 	}
 }
 
+// The typed events below are views into the Event they were read into, so
+// their contents change as soon as that Event is reused for the next call to
+// GetNextEvent(), WaitForEvent() and friends. Use Clone() to get a copy that
+// is safe to hold on to, e.g. for recording input.
+
 /* -- Joystick Axis -- */
 
 type JoystickAxisEvent interface {
 	joystick_axis()
+	Clone() JoystickAxisEvent
 	Timestamp() float64
 	Id() *Joystick
 	Stick() int
@@ -273,6 +416,11 @@ type joystick_axis_event C.struct_ALLEGRO_JOYSTICK_EVENT
 
 func (e *joystick_axis_event) joystick_axis() {}
 
+func (e *joystick_axis_event) Clone() JoystickAxisEvent {
+	c := *e
+	return &c
+}
+
 func (e *joystick_axis_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -297,6 +445,7 @@ func (e *joystick_axis_event) Pos() float32 {
 
 type JoystickButtonDownEvent interface {
 	joystick_button_down()
+	Clone() JoystickButtonDownEvent
 	Timestamp() float64
 	Id() *Joystick
 	Button() int
@@ -306,6 +455,11 @@ type joystick_button_down_event C.struct_ALLEGRO_JOYSTICK_EVENT
 
 func (e *joystick_button_down_event) joystick_button_down() {}
 
+func (e *joystick_button_down_event) Clone() JoystickButtonDownEvent {
+	c := *e
+	return &c
+}
+
 func (e *joystick_button_down_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -322,6 +476,7 @@ func (e *joystick_button_down_event) Button() int {
 
 type JoystickButtonUpEvent interface {
 	joystick_button_up()
+	Clone() JoystickButtonUpEvent
 	Timestamp() float64
 	Id() *Joystick
 	Button() int
@@ -329,7 +484,12 @@ type JoystickButtonUpEvent interface {
 
 type joystick_button_up_event C.struct_ALLEGRO_JOYSTICK_EVENT
 
-func (e *joystick_button_up_event) joystick_button_down() {}
+func (e *joystick_button_up_event) joystick_button_up() {}
+
+func (e *joystick_button_up_event) Clone() JoystickButtonUpEvent {
+	c := *e
+	return &c
+}
 
 func (e *joystick_button_up_event) Timestamp() float64 {
 	return float64(e.timestamp)
@@ -347,6 +507,7 @@ func (e *joystick_button_up_event) Button() int {
 
 type JoystickConfigurationEvent interface {
 	joystick_configuration()
+	Clone() JoystickConfigurationEvent
 	Timestamp() float64
 }
 
@@ -354,6 +515,11 @@ type joystick_configuration_event C.struct_ALLEGRO_JOYSTICK_EVENT
 
 func (e *joystick_configuration_event) joystick_configuration() {}
 
+func (e *joystick_configuration_event) Clone() JoystickConfigurationEvent {
+	c := *e
+	return &c
+}
+
 func (e *joystick_configuration_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -362,6 +528,7 @@ func (e *joystick_configuration_event) Timestamp() float64 {
 
 type KeyDownEvent interface {
 	key_down()
+	Clone() KeyDownEvent
 	Timestamp() float64
 	Source() *Keyboard
 	KeyCode() KeyCode
@@ -372,6 +539,11 @@ type key_down_event C.struct_ALLEGRO_KEYBOARD_EVENT
 
 func (e *key_down_event) key_down() {}
 
+func (e *key_down_event) Clone() KeyDownEvent {
+	c := *e
+	return &c
+}
+
 func (e *key_down_event) Source() *Keyboard {
 	return (*Keyboard)(e.source)
 }
@@ -392,6 +564,7 @@ func (e *key_down_event) Display() *Display {
 
 type KeyUpEvent interface {
 	key_up()
+	Clone() KeyUpEvent
 	Timestamp() float64
 	Source() *Keyboard
 	KeyCode() KeyCode
@@ -402,6 +575,11 @@ type key_up_event C.struct_ALLEGRO_KEYBOARD_EVENT
 
 func (e *key_up_event) key_up() {}
 
+func (e *key_up_event) Clone() KeyUpEvent {
+	c := *e
+	return &c
+}
+
 func (e *key_up_event) Source() *Keyboard {
 	return (*Keyboard)(e.source)
 }
@@ -422,6 +600,7 @@ func (e *key_up_event) Display() *Display {
 
 type KeyCharEvent interface {
 	key_char()
+	Clone() KeyCharEvent
 	Timestamp() float64
 	Source() *Keyboard
 	KeyCode() KeyCode
@@ -435,6 +614,11 @@ type key_char_event C.struct_ALLEGRO_KEYBOARD_EVENT
 
 func (e *key_char_event) key_char() {}
 
+func (e *key_char_event) Clone() KeyCharEvent {
+	c := *e
+	return &c
+}
+
 func (e *key_char_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -467,7 +651,9 @@ func (e *key_char_event) Display() *Display {
 
 type MouseAxesEvent interface {
 	mouse_axes()
+	Clone() MouseAxesEvent
 	Timestamp() float64
+	Source() *Mouse
 	X() int
 	Y() int
 	Z() int
@@ -483,10 +669,19 @@ type mouse_axes_event C.struct_ALLEGRO_MOUSE_EVENT
 
 func (e *mouse_axes_event) mouse_axes() {}
 
+func (e *mouse_axes_event) Clone() MouseAxesEvent {
+	c := *e
+	return &c
+}
+
 func (e *mouse_axes_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
 
+func (e *mouse_axes_event) Source() *Mouse {
+	return (*Mouse)(e.source)
+}
+
 func (e *mouse_axes_event) X() int {
 	return int(e.x)
 }
@@ -527,7 +722,9 @@ func (e *mouse_axes_event) Display() *Display {
 
 type MouseButtonDownEvent interface {
 	mouse_button_down()
+	Clone() MouseButtonDownEvent
 	Timestamp() float64
+	Source() *Mouse
 	X() int
 	Y() int
 	Z() int
@@ -540,10 +737,19 @@ type mouse_button_down_event C.struct_ALLEGRO_MOUSE_EVENT
 
 func (e *mouse_button_down_event) mouse_button_down() {}
 
+func (e *mouse_button_down_event) Clone() MouseButtonDownEvent {
+	c := *e
+	return &c
+}
+
 func (e *mouse_button_down_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
 
+func (e *mouse_button_down_event) Source() *Mouse {
+	return (*Mouse)(e.source)
+}
+
 func (e *mouse_button_down_event) X() int {
 	return int(e.x)
 }
@@ -572,7 +778,9 @@ func (e *mouse_button_down_event) Display() *Display {
 
 type MouseButtonUpEvent interface {
 	mouse_button_up()
+	Clone() MouseButtonUpEvent
 	Timestamp() float64
+	Source() *Mouse
 	X() int
 	Y() int
 	Z() int
@@ -585,10 +793,19 @@ type mouse_button_up_event C.struct_ALLEGRO_MOUSE_EVENT
 
 func (e *mouse_button_up_event) mouse_button_up() {}
 
+func (e *mouse_button_up_event) Clone() MouseButtonUpEvent {
+	c := *e
+	return &c
+}
+
 func (e *mouse_button_up_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
 
+func (e *mouse_button_up_event) Source() *Mouse {
+	return (*Mouse)(e.source)
+}
+
 func (e *mouse_button_up_event) X() int {
 	return int(e.x)
 }
@@ -617,7 +834,9 @@ func (e *mouse_button_up_event) Display() *Display {
 
 type MouseWarpedEvent interface {
 	mouse_warped()
+	Clone() MouseWarpedEvent
 	Timestamp() float64
+	Source() *Mouse
 	X() int
 	Y() int
 	Z() int
@@ -633,10 +852,19 @@ type mouse_warped_event C.struct_ALLEGRO_MOUSE_EVENT
 
 func (e *mouse_warped_event) mouse_warped() {}
 
+func (e *mouse_warped_event) Clone() MouseWarpedEvent {
+	c := *e
+	return &c
+}
+
 func (e *mouse_warped_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
 
+func (e *mouse_warped_event) Source() *Mouse {
+	return (*Mouse)(e.source)
+}
+
 func (e *mouse_warped_event) X() int {
 	return int(e.x)
 }
@@ -677,7 +905,9 @@ func (e *mouse_warped_event) Display() *Display {
 
 type MouseEnterDisplayEvent interface {
 	mouse_enter_display()
+	Clone() MouseEnterDisplayEvent
 	Timestamp() float64
+	Source() *Mouse
 	X() int
 	Y() int
 	Z() int
@@ -689,10 +919,19 @@ type mouse_enter_display_event C.struct_ALLEGRO_MOUSE_EVENT
 
 func (e *mouse_enter_display_event) mouse_enter_display() {}
 
+func (e *mouse_enter_display_event) Clone() MouseEnterDisplayEvent {
+	c := *e
+	return &c
+}
+
 func (e *mouse_enter_display_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
 
+func (e *mouse_enter_display_event) Source() *Mouse {
+	return (*Mouse)(e.source)
+}
+
 func (e *mouse_enter_display_event) X() int {
 	return int(e.x)
 }
@@ -717,7 +956,9 @@ func (e *mouse_enter_display_event) Display() *Display {
 
 type MouseLeaveDisplayEvent interface {
 	mouse_leave_display()
+	Clone() MouseLeaveDisplayEvent
 	Timestamp() float64
+	Source() *Mouse
 	X() int
 	Y() int
 	Z() int
@@ -729,10 +970,19 @@ type mouse_leave_display_event C.struct_ALLEGRO_MOUSE_EVENT
 
 func (e *mouse_leave_display_event) mouse_leave_display() {}
 
+func (e *mouse_leave_display_event) Clone() MouseLeaveDisplayEvent {
+	c := *e
+	return &c
+}
+
 func (e *mouse_leave_display_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
 
+func (e *mouse_leave_display_event) Source() *Mouse {
+	return (*Mouse)(e.source)
+}
+
 func (e *mouse_leave_display_event) X() int {
 	return int(e.x)
 }
@@ -753,10 +1003,255 @@ func (e *mouse_leave_display_event) Display() *Display {
 	return (*Display)(e.display)
 }
 
+/* -- Touch Begin -- */
+
+type TouchBeginEvent interface {
+	touch_begin()
+	Clone() TouchBeginEvent
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_begin_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_begin_event) touch_begin() {}
+
+func (e *touch_begin_event) Clone() TouchBeginEvent {
+	c := *e
+	return &c
+}
+
+func (e *touch_begin_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_begin_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_begin_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_begin_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_begin_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_begin_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_begin_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_begin_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_begin_event) Primary() bool {
+	return bool(e.primary)
+}
+
+/* -- Touch Move -- */
+
+type TouchMoveEvent interface {
+	touch_move()
+	Clone() TouchMoveEvent
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_move_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_move_event) touch_move() {}
+
+func (e *touch_move_event) Clone() TouchMoveEvent {
+	c := *e
+	return &c
+}
+
+func (e *touch_move_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_move_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_move_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_move_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_move_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_move_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_move_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_move_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_move_event) Primary() bool {
+	return bool(e.primary)
+}
+
+/* -- Touch End -- */
+
+type TouchEndEvent interface {
+	touch_end()
+	Clone() TouchEndEvent
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_end_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_end_event) touch_end() {}
+
+func (e *touch_end_event) Clone() TouchEndEvent {
+	c := *e
+	return &c
+}
+
+func (e *touch_end_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_end_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_end_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_end_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_end_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_end_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_end_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_end_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_end_event) Primary() bool {
+	return bool(e.primary)
+}
+
+/* -- Touch Cancel -- */
+
+type TouchCancelEvent interface {
+	touch_cancel()
+	Clone() TouchCancelEvent
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_cancel_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_cancel_event) touch_cancel() {}
+
+func (e *touch_cancel_event) Clone() TouchCancelEvent {
+	c := *e
+	return &c
+}
+
+func (e *touch_cancel_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_cancel_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_cancel_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_cancel_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_cancel_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_cancel_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_cancel_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_cancel_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_cancel_event) Primary() bool {
+	return bool(e.primary)
+}
+
 /* -- Timer -- */
 
 type TimerEvent interface {
 	timer()
+	Clone() TimerEvent
 	Timestamp() float64
 	Source() *Timer
 	Count() int64
@@ -766,6 +1261,11 @@ type timer_event C.struct_ALLEGRO_TIMER_EVENT
 
 func (e *timer_event) timer() {}
 
+func (e *timer_event) Clone() TimerEvent {
+	c := *e
+	return &c
+}
+
 func (e *timer_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -782,6 +1282,7 @@ func (e *timer_event) Count() int64 {
 
 type DisplayExposeEvent interface {
 	display_expose()
+	Clone() DisplayExposeEvent
 	Timestamp() float64
 	Source() *Display
 	X() int
@@ -794,6 +1295,11 @@ type display_expose_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_expose_event) display_expose() {}
 
+func (e *display_expose_event) Clone() DisplayExposeEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_expose_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -822,6 +1328,7 @@ func (e *display_expose_event) Height() int {
 
 type DisplayResizeEvent interface {
 	display_resize()
+	Clone() DisplayResizeEvent
 	Timestamp() float64
 	Source() *Display
 	X() int
@@ -834,6 +1341,11 @@ type display_resize_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_resize_event) display_resize() {}
 
+func (e *display_resize_event) Clone() DisplayResizeEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_resize_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -862,6 +1374,7 @@ func (e *display_resize_event) Height() int {
 
 type DisplayCloseEvent interface {
 	display_close()
+	Clone() DisplayCloseEvent
 	Timestamp() float64
 	Source() *Display
 }
@@ -870,6 +1383,11 @@ type display_close_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_close_event) display_close() {}
 
+func (e *display_close_event) Clone() DisplayCloseEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_close_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -882,6 +1400,7 @@ func (e *display_close_event) Source() *Display {
 
 type DisplayLostEvent interface {
 	display_lost()
+	Clone() DisplayLostEvent
 	Timestamp() float64
 	Source() *Display
 }
@@ -890,6 +1409,11 @@ type display_lost_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_lost_event) display_lost() {}
 
+func (e *display_lost_event) Clone() DisplayLostEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_lost_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -902,6 +1426,7 @@ func (e *display_lost_event) Source() *Display {
 
 type DisplayFoundEvent interface {
 	display_found()
+	Clone() DisplayFoundEvent
 	Timestamp() float64
 	Source() *Display
 }
@@ -910,6 +1435,11 @@ type display_found_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_found_event) display_found() {}
 
+func (e *display_found_event) Clone() DisplayFoundEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_found_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -922,6 +1452,7 @@ func (e *display_found_event) Source() *Display {
 
 type DisplaySwitchOutEvent interface {
 	display_switch_out()
+	Clone() DisplaySwitchOutEvent
 	Timestamp() float64
 	Source() *Display
 }
@@ -930,6 +1461,11 @@ type display_switch_out_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_switch_out_event) display_switch_out() {}
 
+func (e *display_switch_out_event) Clone() DisplaySwitchOutEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_switch_out_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -942,6 +1478,7 @@ func (e *display_switch_out_event) Source() *Display {
 
 type DisplaySwitchInEvent interface {
 	display_switch_in()
+	Clone() DisplaySwitchInEvent
 	Timestamp() float64
 	Source() *Display
 }
@@ -950,6 +1487,11 @@ type display_switch_in_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_switch_in_event) display_switch_in() {}
 
+func (e *display_switch_in_event) Clone() DisplaySwitchInEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_switch_in_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -962,6 +1504,7 @@ func (e *display_switch_in_event) Source() *Display {
 
 type DisplayOrientationEvent interface {
 	display_orientation()
+	Clone() DisplayOrientationEvent
 	Timestamp() float64
 	Source() *Display
 	Orientation() DisplayOrientation
@@ -971,6 +1514,11 @@ type display_orientation_event C.struct_ALLEGRO_DISPLAY_EVENT
 
 func (e *display_orientation_event) display_orientation() {}
 
+func (e *display_orientation_event) Clone() DisplayOrientationEvent {
+	c := *e
+	return &c
+}
+
 func (e *display_orientation_event) Timestamp() float64 {
 	return float64(e.timestamp)
 }
@@ -987,31 +1535,46 @@ func (e *display_orientation_event) Orientation() DisplayOrientation {
 
 type AudioStreamFragment interface {
 	audio_stream_fragment()
+	Clone() AudioStreamFragment
 }
 
 type audio_stream_fragment_event struct{}
 
 func (e *audio_stream_fragment_event) audio_stream_fragment() {}
 
+func (e *audio_stream_fragment_event) Clone() AudioStreamFragment {
+	c := *e
+	return &c
+}
+
 /* -- Audio Stream Finished -- */
 
 type AudioStreamFinished interface {
 	audio_stream_finished()
+	Clone() AudioStreamFinished
 }
 
 type audio_stream_finished_event struct{}
 
 func (e *audio_stream_finished_event) audio_stream_finished() {}
 
+func (e *audio_stream_finished_event) Clone() AudioStreamFinished {
+	c := *e
+	return &c
+}
+
 /* -- User -- */
 
 type UserEvent interface {
 	user()
+	Clone() UserEvent
+	Timestamp() float64
 	Source() *EventSource
 	Data1() uintptr
 	Data2() uintptr
 	Data3() uintptr
 	Data4() uintptr
+	Value() interface{}
 	Unref()
 }
 
@@ -1019,6 +1582,19 @@ type user_event C.struct_ALLEGRO_USER_EVENT
 
 func (e *user_event) user() {}
 
+// The clone captures the value of an event sent with UserEventSource.Emit(),
+// so its Value() stays valid after the original is unreferenced. It holds no
+// reference of its own, and unreferencing it does nothing; the data of other
+// reference counted user events is only valid until the original is
+// unreferenced.
+func (e *user_event) Clone() UserEvent {
+	return &cloned_user_event{user_event: *e, value: e.Value()}
+}
+
+func (e *user_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
 func (e *user_event) Source() *EventSource {
 	return (*EventSource)(e.source)
 }
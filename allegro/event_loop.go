@@ -0,0 +1,290 @@
+package allegro
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Handlers holds the callbacks used by EventQueue.Dispatch(). Any of them may
+// be left nil, in which case events of that type are passed to Other, or
+// dropped if Other is nil too.
+type Handlers struct {
+	JoystickAxis          func(JoystickAxisEvent)
+	JoystickButtonDown    func(JoystickButtonDownEvent)
+	JoystickButtonUp      func(JoystickButtonUpEvent)
+	JoystickConfiguration func(JoystickConfigurationEvent)
+
+	KeyDown func(KeyDownEvent)
+	KeyUp   func(KeyUpEvent)
+	KeyChar func(KeyCharEvent)
+
+	MouseAxes         func(MouseAxesEvent)
+	MouseButtonDown   func(MouseButtonDownEvent)
+	MouseButtonUp     func(MouseButtonUpEvent)
+	MouseWarped       func(MouseWarpedEvent)
+	MouseEnterDisplay func(MouseEnterDisplayEvent)
+	MouseLeaveDisplay func(MouseLeaveDisplayEvent)
+
+	TouchBegin  func(TouchBeginEvent)
+	TouchMove   func(TouchMoveEvent)
+	TouchEnd    func(TouchEndEvent)
+	TouchCancel func(TouchCancelEvent)
+
+	Timer func(TimerEvent)
+
+	DisplayExpose      func(DisplayExposeEvent)
+	DisplayResize      func(DisplayResizeEvent)
+	DisplayClose       func(DisplayCloseEvent)
+	DisplayLost        func(DisplayLostEvent)
+	DisplayFound       func(DisplayFoundEvent)
+	DisplaySwitchOut   func(DisplaySwitchOutEvent)
+	DisplaySwitchIn    func(DisplaySwitchInEvent)
+	DisplayOrientation func(DisplayOrientationEvent)
+
+	// Drop events must be freed by the handler; see DropEvent.
+	Drop func(DropEvent)
+
+	User func(UserEvent)
+
+	// Other receives every event that doesn't have a more specific handler,
+	// including those registered by addons with RegisterEventType().
+	Other func(interface{})
+}
+
+// handle passes the event on to the matching handler.
+func (h *Handlers) handle(e interface{}) {
+	switch e := e.(type) {
+	case DispatchEvent:
+		return
+
+	case JoystickAxisEvent:
+		if h.JoystickAxis != nil {
+			h.JoystickAxis(e)
+			return
+		}
+	case JoystickButtonDownEvent:
+		if h.JoystickButtonDown != nil {
+			h.JoystickButtonDown(e)
+			return
+		}
+	case JoystickButtonUpEvent:
+		if h.JoystickButtonUp != nil {
+			h.JoystickButtonUp(e)
+			return
+		}
+	case JoystickConfigurationEvent:
+		if h.JoystickConfiguration != nil {
+			h.JoystickConfiguration(e)
+			return
+		}
+
+	case KeyDownEvent:
+		if h.KeyDown != nil {
+			h.KeyDown(e)
+			return
+		}
+	case KeyUpEvent:
+		if h.KeyUp != nil {
+			h.KeyUp(e)
+			return
+		}
+	case KeyCharEvent:
+		if h.KeyChar != nil {
+			h.KeyChar(e)
+			return
+		}
+
+	case MouseAxesEvent:
+		if h.MouseAxes != nil {
+			h.MouseAxes(e)
+			return
+		}
+	case MouseButtonDownEvent:
+		if h.MouseButtonDown != nil {
+			h.MouseButtonDown(e)
+			return
+		}
+	case MouseButtonUpEvent:
+		if h.MouseButtonUp != nil {
+			h.MouseButtonUp(e)
+			return
+		}
+	case MouseWarpedEvent:
+		if h.MouseWarped != nil {
+			h.MouseWarped(e)
+			return
+		}
+	case MouseEnterDisplayEvent:
+		if h.MouseEnterDisplay != nil {
+			h.MouseEnterDisplay(e)
+			return
+		}
+	case MouseLeaveDisplayEvent:
+		if h.MouseLeaveDisplay != nil {
+			h.MouseLeaveDisplay(e)
+			return
+		}
+
+	case TouchBeginEvent:
+		if h.TouchBegin != nil {
+			h.TouchBegin(e)
+			return
+		}
+	case TouchMoveEvent:
+		if h.TouchMove != nil {
+			h.TouchMove(e)
+			return
+		}
+	case TouchEndEvent:
+		if h.TouchEnd != nil {
+			h.TouchEnd(e)
+			return
+		}
+	case TouchCancelEvent:
+		if h.TouchCancel != nil {
+			h.TouchCancel(e)
+			return
+		}
+
+	case TimerEvent:
+		if h.Timer != nil {
+			h.Timer(e)
+			return
+		}
+
+	case DisplayExposeEvent:
+		if h.DisplayExpose != nil {
+			h.DisplayExpose(e)
+			return
+		}
+	case DisplayResizeEvent:
+		if h.DisplayResize != nil {
+			h.DisplayResize(e)
+			return
+		}
+	case DisplayCloseEvent:
+		if h.DisplayClose != nil {
+			h.DisplayClose(e)
+			return
+		}
+	case DisplayLostEvent:
+		if h.DisplayLost != nil {
+			h.DisplayLost(e)
+			return
+		}
+	case DisplayFoundEvent:
+		if h.DisplayFound != nil {
+			h.DisplayFound(e)
+			return
+		}
+	case DisplaySwitchOutEvent:
+		if h.DisplaySwitchOut != nil {
+			h.DisplaySwitchOut(e)
+			return
+		}
+	case DisplaySwitchInEvent:
+		if h.DisplaySwitchIn != nil {
+			h.DisplaySwitchIn(e)
+			return
+		}
+	case DisplayOrientationEvent:
+		if h.DisplayOrientation != nil {
+			h.DisplayOrientation(e)
+			return
+		}
+
+	case DropEvent:
+		if h.Drop != nil {
+			h.Drop(e)
+			return
+		}
+
+	case UserEvent:
+		if h.User != nil {
+			h.User(e)
+			return
+		}
+	}
+	if h.Other != nil {
+		h.Other(e)
+	}
+}
+
+// loop waits for events on the queue and passes each one to f, until the
+// context is cancelled. Every event is read into its own Event, so f is free
+// to hold on to it. The calling goroutine is locked to its thread for the
+// duration.
+func (queue *EventQueue) loop(ctx context.Context, f func(interface{})) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	// A private event source is used to wake up the queue once the context
+	// is cancelled, so that it doesn't have to be polled.
+	src := newWakeSource()
+	registerWakeSource(queue, src)
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			wake(src)
+		case <-done:
+		}
+	}()
+	defer func() {
+		close(done)
+		wg.Wait()
+		destroyWakeSource(src)
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		event := new(Event)
+		e := queue.WaitForEvent(event)
+		if d, ok := e.(*dispatch_event); ok && d.Source() == (*EventSource)(src) {
+			continue
+		}
+		f(e)
+	}
+}
+
+// Events() starts reading events from the queue on a new goroutine, and
+// returns a channel that they are sent on. Unlike the events returned by
+// WaitForEvent() and friends, each one is copied out into its own buffer, so
+// they are safe to hold on to. The channel is closed once the context is
+// cancelled.
+//
+// The main loop should call RunPending() whenever it receives a
+// DispatchEvent, since work queued with Do() can't be run on the goroutine
+// reading the queue.
+func (queue *EventQueue) Events(ctx context.Context) <-chan interface{} {
+	events := make(chan interface{})
+	go func() {
+		defer close(events)
+		queue.loop(ctx, func(e interface{}) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return events
+}
+
+// Dispatch() reads events from the queue and passes each one to the matching
+// callback in handlers, until the context is cancelled. It runs on the
+// calling goroutine, so when called from the main thread the callbacks are
+// free to draw, and work queued with Do() is run as it arrives. The
+// context's error is returned.
+func (queue *EventQueue) Dispatch(ctx context.Context, handlers Handlers) error {
+	return queue.loop(ctx, func(e interface{}) {
+		handlers.handle(e)
+	})
+}
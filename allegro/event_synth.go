@@ -0,0 +1,192 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+/*
+enum { NAG_SYNTHETIC_USER_EVENT = ALLEGRO_GET_EVENT_TYPE('N', 'A', 'G', 'U') };
+*/
+import "C"
+import (
+	"unsafe"
+)
+
+// The methods in this file fill in an Event by hand, and return the typed
+// event for it just like WaitForEvent() would. They don't touch Allegro
+// itself, so they can be used without initialising it, which makes them
+// useful for feeding synthetic input to code under test or replaying
+// recorded input. Any fields that aren't set are zeroed.
+
+func (e *Event) joystick(t C.ALLEGRO_EVENT_TYPE, timestamp float64, id *Joystick) *C.ALLEGRO_JOYSTICK_EVENT {
+	*e = Event{}
+	j := (*C.ALLEGRO_JOYSTICK_EVENT)(unsafe.Pointer(e))
+	j._type = t
+	j.timestamp = C.double(timestamp)
+	j.id = (*C.ALLEGRO_JOYSTICK)(id)
+	return j
+}
+
+func (e *Event) keyboard(t C.ALLEGRO_EVENT_TYPE, timestamp float64, keycode KeyCode, display *Display) *C.ALLEGRO_KEYBOARD_EVENT {
+	*e = Event{}
+	k := (*C.ALLEGRO_KEYBOARD_EVENT)(unsafe.Pointer(e))
+	k._type = t
+	k.timestamp = C.double(timestamp)
+	k.keycode = C.int(keycode)
+	k.display = (*C.ALLEGRO_DISPLAY)(display)
+	return k
+}
+
+func (e *Event) mouse(t C.ALLEGRO_EVENT_TYPE, timestamp float64, x, y, z, w int, display *Display) *C.ALLEGRO_MOUSE_EVENT {
+	*e = Event{}
+	m := (*C.ALLEGRO_MOUSE_EVENT)(unsafe.Pointer(e))
+	m._type = t
+	m.timestamp = C.double(timestamp)
+	m.x, m.y, m.z, m.w = C.int(x), C.int(y), C.int(z), C.int(w)
+	m.display = (*C.ALLEGRO_DISPLAY)(display)
+	return m
+}
+
+func (e *Event) touch(t C.ALLEGRO_EVENT_TYPE, timestamp float64, id int, x, y, dx, dy float32, primary bool, display *Display) {
+	*e = Event{}
+	te := (*C.ALLEGRO_TOUCH_EVENT)(unsafe.Pointer(e))
+	te._type = t
+	te.timestamp = C.double(timestamp)
+	te.id = C.int(id)
+	te.x, te.y, te.dx, te.dy = C.float(x), C.float(y), C.float(dx), C.float(dy)
+	te.primary = C.bool(primary)
+	te.display = (*C.ALLEGRO_DISPLAY)(display)
+}
+
+// SetJoystickAxis() turns e into a joystick axis event.
+func (e *Event) SetJoystickAxis(timestamp float64, id *Joystick, stick, axis int, pos float32) JoystickAxisEvent {
+	j := e.joystick(C.ALLEGRO_EVENT_JOYSTICK_AXIS, timestamp, id)
+	j.stick, j.axis, j.pos = C.int(stick), C.int(axis), C.float(pos)
+	return e.cast().(JoystickAxisEvent)
+}
+
+// SetJoystickButtonDown() turns e into a joystick button down event.
+func (e *Event) SetJoystickButtonDown(timestamp float64, id *Joystick, button int) JoystickButtonDownEvent {
+	j := e.joystick(C.ALLEGRO_EVENT_JOYSTICK_BUTTON_DOWN, timestamp, id)
+	j.button = C.int(button)
+	return e.cast().(JoystickButtonDownEvent)
+}
+
+// SetJoystickButtonUp() turns e into a joystick button up event.
+func (e *Event) SetJoystickButtonUp(timestamp float64, id *Joystick, button int) JoystickButtonUpEvent {
+	j := e.joystick(C.ALLEGRO_EVENT_JOYSTICK_BUTTON_UP, timestamp, id)
+	j.button = C.int(button)
+	return e.cast().(JoystickButtonUpEvent)
+}
+
+// SetJoystickConfiguration() turns e into a joystick configuration event.
+func (e *Event) SetJoystickConfiguration(timestamp float64) JoystickConfigurationEvent {
+	e.joystick(C.ALLEGRO_EVENT_JOYSTICK_CONFIGURATION, timestamp, nil)
+	return e.cast().(JoystickConfigurationEvent)
+}
+
+// SetKeyDown() turns e into a key down event.
+func (e *Event) SetKeyDown(timestamp float64, keycode KeyCode, display *Display) KeyDownEvent {
+	e.keyboard(C.ALLEGRO_EVENT_KEY_DOWN, timestamp, keycode, display)
+	return e.cast().(KeyDownEvent)
+}
+
+// SetKeyUp() turns e into a key up event.
+func (e *Event) SetKeyUp(timestamp float64, keycode KeyCode, display *Display) KeyUpEvent {
+	e.keyboard(C.ALLEGRO_EVENT_KEY_UP, timestamp, keycode, display)
+	return e.cast().(KeyUpEvent)
+}
+
+// SetKeyChar() turns e into a key char event.
+func (e *Event) SetKeyChar(timestamp float64, keycode KeyCode, unichar int, modifiers KeyModifier, repeat bool, display *Display) KeyCharEvent {
+	k := e.keyboard(C.ALLEGRO_EVENT_KEY_CHAR, timestamp, keycode, display)
+	k.unichar = C.int(unichar)
+	k.modifiers = C.uint(modifiers)
+	k.repeat = C.bool(repeat)
+	return e.cast().(KeyCharEvent)
+}
+
+// SetMouseAxes() turns e into a mouse axes event.
+func (e *Event) SetMouseAxes(timestamp float64, x, y, z, w, dx, dy, dz, dw int, display *Display) MouseAxesEvent {
+	m := e.mouse(C.ALLEGRO_EVENT_MOUSE_AXES, timestamp, x, y, z, w, display)
+	m.dx, m.dy, m.dz, m.dw = C.int(dx), C.int(dy), C.int(dz), C.int(dw)
+	return e.cast().(MouseAxesEvent)
+}
+
+// SetMouseWarped() turns e into a mouse warped event.
+func (e *Event) SetMouseWarped(timestamp float64, x, y, z, w, dx, dy, dz, dw int, display *Display) MouseWarpedEvent {
+	m := e.mouse(C.ALLEGRO_EVENT_MOUSE_WARPED, timestamp, x, y, z, w, display)
+	m.dx, m.dy, m.dz, m.dw = C.int(dx), C.int(dy), C.int(dz), C.int(dw)
+	return e.cast().(MouseWarpedEvent)
+}
+
+// SetMouseButtonDown() turns e into a mouse button down event.
+func (e *Event) SetMouseButtonDown(timestamp float64, x, y, z, w int, button uint, display *Display) MouseButtonDownEvent {
+	m := e.mouse(C.ALLEGRO_EVENT_MOUSE_BUTTON_DOWN, timestamp, x, y, z, w, display)
+	m.button = C.uint(button)
+	return e.cast().(MouseButtonDownEvent)
+}
+
+// SetMouseButtonUp() turns e into a mouse button up event.
+func (e *Event) SetMouseButtonUp(timestamp float64, x, y, z, w int, button uint, display *Display) MouseButtonUpEvent {
+	m := e.mouse(C.ALLEGRO_EVENT_MOUSE_BUTTON_UP, timestamp, x, y, z, w, display)
+	m.button = C.uint(button)
+	return e.cast().(MouseButtonUpEvent)
+}
+
+// SetTouchBegin() turns e into a touch begin event.
+func (e *Event) SetTouchBegin(timestamp float64, id int, x, y, dx, dy float32, primary bool, display *Display) TouchBeginEvent {
+	e.touch(C.ALLEGRO_EVENT_TOUCH_BEGIN, timestamp, id, x, y, dx, dy, primary, display)
+	return e.cast().(TouchBeginEvent)
+}
+
+// SetTouchMove() turns e into a touch move event.
+func (e *Event) SetTouchMove(timestamp float64, id int, x, y, dx, dy float32, primary bool, display *Display) TouchMoveEvent {
+	e.touch(C.ALLEGRO_EVENT_TOUCH_MOVE, timestamp, id, x, y, dx, dy, primary, display)
+	return e.cast().(TouchMoveEvent)
+}
+
+// SetTouchEnd() turns e into a touch end event.
+func (e *Event) SetTouchEnd(timestamp float64, id int, x, y, dx, dy float32, primary bool, display *Display) TouchEndEvent {
+	e.touch(C.ALLEGRO_EVENT_TOUCH_END, timestamp, id, x, y, dx, dy, primary, display)
+	return e.cast().(TouchEndEvent)
+}
+
+// SetTouchCancel() turns e into a touch cancel event.
+func (e *Event) SetTouchCancel(timestamp float64, id int, x, y, dx, dy float32, primary bool, display *Display) TouchCancelEvent {
+	e.touch(C.ALLEGRO_EVENT_TOUCH_CANCEL, timestamp, id, x, y, dx, dy, primary, display)
+	return e.cast().(TouchCancelEvent)
+}
+
+// SetTimer() turns e into a timer event.
+func (e *Event) SetTimer(timestamp float64, source *Timer, count int64) TimerEvent {
+	*e = Event{}
+	t := (*C.ALLEGRO_TIMER_EVENT)(unsafe.Pointer(e))
+	t._type = C.ALLEGRO_EVENT_TIMER
+	t.timestamp = C.double(timestamp)
+	t.source = (*C.ALLEGRO_TIMER)(source)
+	t.count = C.int64_t(count)
+	return e.cast().(TimerEvent)
+}
+
+// SetDisplayClose() turns e into a display close event.
+func (e *Event) SetDisplayClose(timestamp float64, source *Display) DisplayCloseEvent {
+	*e = Event{}
+	d := (*C.ALLEGRO_DISPLAY_EVENT)(unsafe.Pointer(e))
+	d._type = C.ALLEGRO_EVENT_DISPLAY_CLOSE
+	d.timestamp = C.double(timestamp)
+	d.source = (*C.ALLEGRO_DISPLAY)(source)
+	return e.cast().(DisplayCloseEvent)
+}
+
+// SetUser() turns e into a user event carrying up to four data fields. The
+// event isn't reference counted, so calling Unref() on it does nothing.
+func (e *Event) SetUser(timestamp float64, source *EventSource, data ...uintptr) UserEvent {
+	*e = Event{}
+	u := (*C.ALLEGRO_USER_EVENT)(unsafe.Pointer(e))
+	u._type = C.NAG_SYNTHETIC_USER_EVENT
+	u.timestamp = C.double(timestamp)
+	u.source = (*C.ALLEGRO_EVENT_SOURCE)(source)
+	fields := []*C.intptr_t{&u.data1, &u.data2, &u.data3, &u.data4}
+	for i := 0; i < len(data) && i < len(fields); i++ {
+		*fields[i] = C.intptr_t(data[i])
+	}
+	return e.cast().(UserEvent)
+}
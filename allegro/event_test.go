@@ -0,0 +1,64 @@
+package allegro
+
+import "testing"
+
+func TestUserEventClone(t *testing.T) {
+	if err := Init(NoAtExit()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+	queue, err := CreateEventQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Destroy()
+	source := NewUserEventSource()
+	defer source.Destroy()
+	queue.RegisterEventSource(source.EventSource())
+
+	if err := source.Emit("hello"); err != nil {
+		t.Fatal(err)
+	}
+	var event Event
+	queue.WaitForEventInto(&event)
+	ev, ok := event.Typed().(UserEvent)
+	if !ok {
+		t.Fatalf("got %T, want a UserEvent", event.Typed())
+	}
+	clone := ev.Clone()
+	ev.Unref()
+	// The value outlives the original's reference.
+	if v := clone.Value(); v != "hello" {
+		t.Errorf("clone carries %v after the original was unreferenced", v)
+	}
+	clone.Unref()
+}
+
+func TestWaitForEventIntoAllocs(t *testing.T) {
+	if err := Init(NoAtExit()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown()
+	queue, err := CreateEventQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Destroy()
+	timer, err := CreateTimer(0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer timer.Destroy()
+	queue.RegisterEventSource(timer.EventSource())
+	timer.Start()
+
+	var events [16]Event
+	i := 0
+	allocs := testing.AllocsPerRun(10, func() {
+		queue.WaitForEventInto(&events[i%len(events)])
+		i++
+	})
+	if allocs != 0 {
+		t.Errorf("WaitForEventInto() allocates %v times per event", allocs)
+	}
+}
@@ -4,9 +4,10 @@ package allegro
 import "C"
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
+	"runtime"
+	"syscall"
 	"unsafe"
 )
 
@@ -52,9 +53,16 @@ func OpenFile(path string, mode FileMode) (*File, error) {
 	mode_ := C.CString(mode.String())
 	defer freeString(path_)
 	defer freeString(mode_)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	f := C.al_fopen(path_, mode_)
 	if f == nil {
-		return nil, fmt.Errorf("failed to open File '%s'", path)
+		err := NewError("", "open file", path, nil)
+		if err.Errno == syscall.ENOENT {
+			err.Err = ErrNotFound
+		}
+		return nil, err
 	}
 	return (*File)(f), nil
 }
@@ -70,9 +78,11 @@ func MakeTempFile(template string) string {
 
 // Close the given file, writing any buffered output data (if any).
 func (f *File) Close() error {
-	C.al_fclose((*C.ALLEGRO_FILE)(f))
-	if f.HasError() {
-		return LastError()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
+	if !bool(C.al_fclose((*C.ALLEGRO_FILE)(f))) {
+		return NewError("", "close file", "", nil)
 	}
 	return nil
 }
@@ -95,18 +105,22 @@ func (f *File) ClearError() {
 }
 
 // Read 'size' bytes into the buffer pointed to by 'ptr', from the given file.
+// Reading into an empty buffer does nothing, as with any io.Reader.
 func (f *File) Read(b []byte) (n int, err error) {
 	size := len(b)
 	if size == 0 {
-		return 0, errors.New("cannot read into empty buffer")
+		return 0, nil
 	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	r := int(C.al_fread((*C.ALLEGRO_FILE)(f),
 		unsafe.Pointer(&b[0]),
 		C.size_t(size)))
 	if r == 0 && f.Eof() {
 		return r, io.EOF
 	} else if f.HasError() {
-		return r, errors.New("read() encountered an error")
+		return r, NewError("", "read file", "", nil)
 	} else {
 		return r, nil
 	}
@@ -115,11 +129,17 @@ func (f *File) Read(b []byte) (n int, err error) {
 // Write 'size' bytes from the buffer pointed to by 'ptr' into the given file.
 func (f *File) Write(b []byte) (n int, err error) {
 	size := len(b)
+	if size == 0 {
+		return 0, nil
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	written := int(C.al_fwrite((*C.ALLEGRO_FILE)(f),
 		unsafe.Pointer(&b[0]),
 		C.size_t(size)))
 	if written < size {
-		return written, errors.New("write() encountered an error")
+		return written, NewError("", "write file", "", nil)
 	} else {
 		return written, nil
 	}
@@ -128,11 +148,14 @@ func (f *File) Write(b []byte) (n int, err error) {
 // Read and return next byte in the given file. Returns EOF on end of file or
 // if an error occurred.
 func (f *File) Getc() (byte, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	b := byte(C.al_fgetc((*C.ALLEGRO_FILE)(f)))
 	if f.Eof() {
 		return 0, io.EOF
 	} else if f.HasError() {
-		return 0, errors.New("error occurred when reading byte")
+		return 0, NewError("", "read file", "", nil)
 	} else {
 		return b, nil
 	}
@@ -152,9 +175,12 @@ func (f *File) Putc(b byte) byte {
 
 // Flush any pending writes to the given file.
 func (f *File) Flush() error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	ok := bool(C.al_fflush((*C.ALLEGRO_FILE)(f)))
 	if !ok {
-		return LastError()
+		return NewError("", "flush file", "", nil)
 	}
 	return nil
 }
@@ -162,9 +188,12 @@ func (f *File) Flush() error {
 // Returns the current position in the given file, or -1 on error. errno is set
 // to indicate the error.
 func (f *File) Tell() (int64, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	pos := int64(C.al_ftell((*C.ALLEGRO_FILE)(f)))
 	if pos == -1 {
-		return 0, LastError()
+		return 0, NewError("", "tell file position", "", nil)
 	}
 	return pos, nil
 }
@@ -183,9 +212,12 @@ func (f *File) Seek(offset int64, whence int) (ret int64, err error) {
 	default:
 		return 0, fmt.Errorf("unrecognized whence value: %d", whence)
 	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	ok := bool(C.al_fseek((*C.ALLEGRO_FILE)(f), C.int64_t(offset), whence_))
 	if !ok {
-		return 0, LastError()
+		return 0, NewError("", "seek file", "", nil)
 	}
 	pos, err := f.Tell()
 	if err != nil {
@@ -196,9 +228,12 @@ func (f *File) Seek(offset int64, whence int) (ret int64, err error) {
 
 // Return the size of the file, if it can be determined, or -1 otherwise.
 func (f *File) Size() (int64, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	size := int64(C.al_fsize((*C.ALLEGRO_FILE)(f)))
 	if size == -1 {
-		return 0, errors.New("File size could not be determined")
+		return 0, NewError("", "get file size", "", nil)
 	}
 	return size, nil
 }
@@ -211,7 +246,7 @@ func (f *File) Slice(initial_size int, mode FileMode) (*File, error) {
 	defer freeString(mode_)
 	s := C.al_fopen_slice((*C.ALLEGRO_FILE)(f), C.size_t(initial_size), mode_)
 	if s == nil {
-		return nil, errors.New("failed to slice File")
+		return nil, &Error{Op: "slice file"}
 	}
 	return (*File)(s), nil
 }
@@ -4,6 +4,16 @@ package font
 // #include <allegro5/allegro.h>
 // #include <allegro5/allegro_font.h>
 // #include "../util.c"
+/*
+// al_is_font_addon_initialized() only exists from Allegro 5.2.6 on.
+static bool font_initialized(void) {
+#if ALLEGRO_VERSION_INT >= AL_ID(5, 2, 6, 0)
+	return al_is_font_addon_initialized();
+#else
+	return true;
+#endif
+}
+*/
 import "C"
 import (
 	"errors"
@@ -25,6 +35,14 @@ const (
 	ALIGN_INTEGER           = C.ALLEGRO_ALIGN_INTEGER
 )
 
+// Allegro destroys all fonts when the addon is shut down.
+var lifetime allegro.Lifetime
+
+func init() {
+	// Fonts hold on to bitmaps of their glyphs.
+	allegro.RegisterResource((*Font)(nil), -1, &lifetime)
+}
+
 // Initialise the font addon.
 func Install() {
 	C.al_init_font_addon()
@@ -34,6 +52,7 @@ func Install() {
 // can be called any time the user wishes as well.
 func Uninstall() {
 	C.al_shutdown_font_addon()
+	lifetime.End()
 }
 
 // Returns the (compiled) version of the addon, in the same format as
@@ -51,19 +70,28 @@ func Version() (major, minor, revision, release uint8) {
 func Builtin() (*Font, error) {
 	f := C.al_create_builtin_font()
 	if f == nil {
-		return nil, errors.New("failed to create builtin font")
+		return nil, &allegro.Error{Op: "create builtin font", Addon: "font"}
 	}
 	return (*Font)(f), nil
 }
 
+// installed() returns true if the addon has been initialized. Allegro older
+// than 5.2.6 can't tell, so it's assumed to be.
+func installed() bool {
+	return bool(C.font_initialized())
+}
+
 // Loads a font from disk. This will use al_load_bitmap_font if you pass the
 // name of a known bitmap format, or else al_load_ttf_font.
 func LoadFont(filename string, size, flags int) (*Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !installed() {
+		return nil, &allegro.Error{Op: "load font", Path: filename, Addon: "font", Err: allegro.ErrNotInstalled}
+	}
 	f := C.al_load_font(filename_, C.int(size), C.int(flags))
 	if f == nil {
-		return nil, fmt.Errorf("failed to load font '%s'", filename)
+		return nil, allegro.LoadError("font", "load font", filename)
 	}
 	font := (*Font)(f)
 	//runtime.SetFinalizer(font, font.Destroy)
@@ -77,9 +105,12 @@ func LoadFont(filename string, size, flags int) (*Font, error) {
 func LoadBitmapFont(filename string) (*Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !installed() {
+		return nil, &allegro.Error{Op: "load bitmap font", Path: filename, Addon: "font", Err: allegro.ErrNotInstalled}
+	}
 	f := C.al_load_bitmap_font(filename_)
 	if f == nil {
-		return nil, fmt.Errorf("failed to load bitmap font '%s'", filename)
+		return nil, allegro.LoadError("font", "load bitmap font", filename)
 	}
 	font := (*Font)(f)
 	//runtime.SetFinalizer(font, font.Destroy)
@@ -105,7 +136,7 @@ func GrabFontFromBitmap(bmp *allegro.Bitmap, ranges [][2]int) (*Font, error) {
 
 	f := C.al_grab_font_from_bitmap((*C.ALLEGRO_BITMAP)(unsafe.Pointer(bmp)), C.int(nRanges), (*C.int)(unsafe.Pointer(&c_ranges[0])))
 	if f == nil {
-		return nil, errors.New("failed to grab font from bitmap")
+		return nil, &allegro.Error{Op: "grab font from bitmap", Addon: "font"}
 	}
 	return (*Font)(f), nil
 }
@@ -4,10 +4,19 @@ package ttf
 // #include <allegro5/allegro.h>
 // #include <allegro5/allegro_ttf.h>
 // #include "../../util.c"
+/*
+// al_is_ttf_addon_initialized() only exists from Allegro 5.2.6 on.
+static bool ttf_initialized(void) {
+#if ALLEGRO_VERSION_INT >= AL_ID(5, 2, 6, 0)
+	return al_is_ttf_addon_initialized();
+#else
+	return true;
+#endif
+}
+*/
 import "C"
 import (
-	"errors"
-	"fmt"
+	"runtime"
 	"unsafe"
 
 	"github.com/phrasz/nag/allegro"
@@ -44,14 +53,23 @@ func Version() (major, minor, revision, release uint8) {
 	return
 }
 
+// installed() returns true if the addon has been initialized. Allegro older
+// than 5.2.6 can't tell, so it's assumed to be.
+func installed() bool {
+	return bool(C.ttf_initialized())
+}
+
 // Loads a TrueType font from a file using the FreeType library. Quoting from
 // the FreeType FAQ this means support for many different font formats:
 func LoadFont(filename string, size int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !installed() {
+		return nil, &allegro.Error{Op: "load ttf font", Path: filename, Addon: "ttf", Err: allegro.ErrNotInstalled}
+	}
 	f := C.al_load_ttf_font(filename_, C.int(size), C.int(flags))
 	if f == nil {
-		return nil, fmt.Errorf("failed to load ttf font at '%s'", filename)
+		return nil, allegro.LoadError("ttf", "load ttf font", filename)
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
@@ -61,10 +79,16 @@ func LoadFont(filename string, size int, flags TtfFlags) (*font.Font, error) {
 func LoadFontF(file *allegro.File, filename string, size int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !installed() {
+		return nil, &allegro.Error{Op: "load ttf font", Path: filename, Addon: "ttf", Err: allegro.ErrNotInstalled}
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	f := C.al_load_ttf_font_f((*C.ALLEGRO_FILE)(unsafe.Pointer(file)), filename_,
 		C.int(size), C.int(flags))
 	if f == nil {
-		return nil, errors.New("failed to load font from file")
+		return nil, allegro.LoadFileError("ttf", "load ttf font", filename)
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
@@ -74,9 +98,12 @@ func LoadFontF(file *allegro.File, filename string, size int, flags TtfFlags) (*
 func LoadFontStretch(filename string, w, h int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !installed() {
+		return nil, &allegro.Error{Op: "load ttf font", Path: filename, Addon: "ttf", Err: allegro.ErrNotInstalled}
+	}
 	f := C.al_load_ttf_font_stretch(filename_, C.int(w), C.int(h), C.int(flags))
 	if f == nil {
-		return nil, fmt.Errorf("failed to load ttf font at '%s'", filename)
+		return nil, allegro.LoadError("ttf", "load ttf font", filename)
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
@@ -87,10 +114,16 @@ func LoadFontStretch(filename string, w, h int, flags TtfFlags) (*font.Font, err
 func LoadFontStretchF(file *allegro.File, filename string, w, h int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
+	if !installed() {
+		return nil, &allegro.Error{Op: "load ttf font", Path: filename, Addon: "ttf", Err: allegro.ErrNotInstalled}
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	f := C.al_load_ttf_font_stretch_f((*C.ALLEGRO_FILE)(unsafe.Pointer(file)),
 		filename_, C.int(w), C.int(h), C.int(flags))
 	if f == nil {
-		return nil, errors.New("failed to load font from file")
+		return nil, allegro.LoadFileError("ttf", "load ttf font", filename)
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
@@ -0,0 +1,300 @@
+package gamepad
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// Button is a button of the standard gamepad layout.
+type Button int
+
+const (
+	ButtonA Button = iota
+	ButtonB
+	ButtonX
+	ButtonY
+	ButtonBack
+	ButtonGuide
+	ButtonStart
+	ButtonLeftStick
+	ButtonRightStick
+	ButtonLeftShoulder
+	ButtonRightShoulder
+	ButtonDPadUp
+	ButtonDPadDown
+	ButtonDPadLeft
+	ButtonDPadRight
+	ButtonCount
+)
+
+// Axis is an axis of the standard gamepad layout. Sticks go from -1 to 1,
+// with negative values being up or left, and triggers go from 0 to 1.
+type Axis int
+
+const (
+	LeftStickX Axis = iota
+	LeftStickY
+	RightStickX
+	RightStickY
+	LeftTrigger
+	RightTrigger
+	AxisCount
+)
+
+// The names used for buttons and axes in the database.
+var (
+	buttonNames = [ButtonCount]string{
+		"a", "b", "x", "y", "back", "guide", "start", "leftstick", "rightstick",
+		"leftshoulder", "rightshoulder", "dpup", "dpdown", "dpleft", "dpright",
+	}
+	axisNames = [AxisCount]string{
+		"leftx", "lefty", "rightx", "righty", "lefttrigger", "righttrigger",
+	}
+)
+
+func (b Button) String() string {
+	if b >= 0 && b < ButtonCount {
+		return buttonNames[b]
+	}
+	return "unknown"
+}
+
+func (a Axis) String() string {
+	if a >= 0 && a < AxisCount {
+		return axisNames[a]
+	}
+	return "unknown"
+}
+
+type sourceKind int
+
+const (
+	rawButton sourceKind = iota + 1
+	rawAxis
+	rawHat
+)
+
+// source is one raw input that a button or axis of the standard layout is
+// mapped from.
+type source struct {
+	kind  sourceKind
+	index int
+
+	// hatMask selects the direction of a hat: 1 is up, 2 right, 4 down and
+	// 8 left.
+	hatMask int
+
+	// half is +1 or -1 when only half of a raw axis is used, and invert
+	// flips a raw axis.
+	half   int
+	invert bool
+
+	// outHalf is +1 or -1 when the source only drives half of an axis of
+	// the standard layout.
+	outHalf int
+}
+
+// Mapping maps the raw buttons, axes and hats of one joystick model onto the
+// standard gamepad layout. Raw axes are numbered across all of a joystick's
+// analog sticks, and hats are the digital ones; see Layout.
+type Mapping struct {
+	GUID     string
+	Name     string
+	Platform string
+
+	buttons [ButtonCount][]source
+	axes    [AxisCount][]source
+}
+
+func parseSource(s string) (source, error) {
+	var src source
+	if strings.HasPrefix(s, "+") {
+		src.half, s = 1, s[1:]
+	} else if strings.HasPrefix(s, "-") {
+		src.half, s = -1, s[1:]
+	}
+	if strings.HasSuffix(s, "~") {
+		src.invert, s = true, s[:len(s)-1]
+	}
+	if len(s) < 2 {
+		return src, fmt.Errorf("invalid input '%s'", s)
+	}
+	var err error
+	switch s[0] {
+	case 'b':
+		src.kind = rawButton
+		src.index, err = strconv.Atoi(s[1:])
+	case 'a':
+		src.kind = rawAxis
+		src.index, err = strconv.Atoi(s[1:])
+	case 'h':
+		src.kind = rawHat
+		hat, mask, ok := strings.Cut(s[1:], ".")
+		if !ok {
+			return src, fmt.Errorf("invalid hat '%s'", s)
+		}
+		if src.index, err = strconv.Atoi(hat); err == nil {
+			src.hatMask, err = strconv.Atoi(mask)
+		}
+	default:
+		return src, fmt.Errorf("invalid input '%s'", s)
+	}
+	if err != nil {
+		return src, fmt.Errorf("invalid input '%s'", s)
+	}
+	return src, nil
+}
+
+// ParseMapping() parses a single line of a gamecontrollerdb.txt file, in the
+// form "GUID,name,a:b0,b:b1,leftx:a0,...". Elements that don't belong to the
+// standard layout are ignored.
+func ParseMapping(line string) (*Mapping, error) {
+	fields := strings.Split(strings.TrimSpace(line), ",")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid mapping '%s'", line)
+	}
+	m := &Mapping{GUID: strings.ToLower(fields[0]), Name: fields[1]}
+elements:
+	for _, f := range fields[2:] {
+		if f == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(f, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid element '%s' in mapping for '%s'", f, m.Name)
+		}
+		if key == "platform" {
+			m.Platform = value
+			continue
+		}
+		outHalf := 0
+		if strings.HasPrefix(key, "+") {
+			outHalf, key = 1, key[1:]
+		} else if strings.HasPrefix(key, "-") {
+			outHalf, key = -1, key[1:]
+		}
+		for b, name := range buttonNames {
+			if key == name {
+				src, err := parseSource(value)
+				if err != nil {
+					return nil, fmt.Errorf("%v in mapping for '%s'", err, m.Name)
+				}
+				m.buttons[b] = append(m.buttons[b], src)
+				continue elements
+			}
+		}
+		for a, name := range axisNames {
+			if key == name {
+				src, err := parseSource(value)
+				if err != nil {
+					return nil, fmt.Errorf("%v in mapping for '%s'", err, m.Name)
+				}
+				src.outHalf = outHalf
+				m.axes[a] = append(m.axes[a], src)
+				continue elements
+			}
+		}
+	}
+	return m, nil
+}
+
+// GameControllerDB is a set of mappings, as found in SDL's
+// gamecontrollerdb.txt, looked up by joystick GUID or name.
+type GameControllerDB struct {
+	// Platform is the platform whose mappings are kept by Parse(), using
+	// the database's names for them. It defaults to the current one.
+	Platform string
+
+	byGUID map[string]*Mapping
+	byName map[string]*Mapping
+}
+
+func currentPlatform() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Windows"
+	case "darwin":
+		return "Mac OS X"
+	case "android":
+		return "Android"
+	case "ios":
+		return "iOS"
+	}
+	return "Linux"
+}
+
+// NewGameControllerDB() creates an empty database.
+func NewGameControllerDB() *GameControllerDB {
+	return &GameControllerDB{
+		Platform: currentPlatform(),
+		byGUID:   make(map[string]*Mapping),
+		byName:   make(map[string]*Mapping),
+	}
+}
+
+// LoadGameControllerDB() creates a database from a gamecontrollerdb.txt file.
+func LoadGameControllerDB(filename string) (*GameControllerDB, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	db := NewGameControllerDB()
+	return db, db.Parse(f)
+}
+
+// Add() adds a mapping to the database, replacing any earlier mapping with
+// the same GUID or name.
+func (db *GameControllerDB) Add(m *Mapping) {
+	if m.GUID != "" {
+		db.byGUID[m.GUID] = m
+	}
+	if m.Name != "" {
+		db.byName[m.Name] = m
+	}
+}
+
+// Parse() adds every mapping for the database's platform in r, which is in
+// the gamecontrollerdb.txt format. Mappings that don't name a platform are
+// always added.
+func (db *GameControllerDB) Parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m, err := ParseMapping(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+		if m.Platform == "" || m.Platform == db.Platform {
+			db.Add(m)
+		}
+	}
+	return scanner.Err()
+}
+
+// Lookup() finds the mapping for a joystick by GUID, falling back on its
+// name.
+func (db *GameControllerDB) Lookup(guid, name string) (*Mapping, bool) {
+	if m, ok := db.byGUID[strings.ToLower(guid)]; ok && guid != "" {
+		return m, true
+	}
+	m, ok := db.byName[name]
+	return m, ok
+}
+
+// ForJoystick() finds the mapping for a joystick. Its GUID is used when the
+// version of Allegro provides it, and its name otherwise.
+func (db *GameControllerDB) ForJoystick(j *allegro.Joystick) (*Mapping, bool) {
+	guid, _ := j.GUID()
+	return db.Lookup(guid, j.Name())
+}
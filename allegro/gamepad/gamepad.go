@@ -0,0 +1,289 @@
+// Package gamepad presents joysticks through a standard gamepad layout, with
+// an A button, a left stick and so on, using the mappings from SDL's game
+// controller database to translate each model's raw buttons and axes.
+//
+//	db, err := gamepad.LoadGameControllerDB("gamecontrollerdb.txt")
+//	...
+//	if m, ok := db.ForJoystick(joy); ok {
+//		pad := gamepad.New(joy, m)
+//		for _, e := range pad.Handle(event) {
+//			switch e := e.(type) {
+//			case gamepad.ButtonEvent:
+//				if e.Button == gamepad.ButtonA && e.Pressed {
+//					player.Jump()
+//				}
+//			}
+//		}
+//	}
+package gamepad
+
+import (
+	"github.com/phrasz/nag/allegro"
+)
+
+// StickLayout describes one of a joystick's sticks.
+type StickLayout struct {
+	Axes int
+
+	// Hat is true for digital sticks, which are treated as hats rather
+	// than as axes.
+	Hat bool
+}
+
+// Layout describes how a joystick's sticks map onto the flat numbering of
+// axes and hats used by the database: the axes of the analog sticks are
+// numbered in order, and so are the digital sticks, which are taken to be
+// hats. This matches how most drivers report gamepads, but isn't guaranteed.
+type Layout struct {
+	Sticks []StickLayout
+}
+
+// LayoutOf() returns the layout of a joystick.
+func LayoutOf(j *allegro.Joystick) Layout {
+	var l Layout
+	for s := 0; s < j.NumSticks(); s++ {
+		l.Sticks = append(l.Sticks, StickLayout{
+			Axes: j.NumAxes(s),
+			Hat:  j.StickFlags(s)&allegro.JOYFLAG_DIGITAL != 0,
+		})
+	}
+	return l
+}
+
+// locate() returns the raw axis or hat a stick's axis belongs to.
+func (l *Layout) locate(stick, axis int) (index int, hat bool, ok bool) {
+	if stick < 0 || stick >= len(l.Sticks) {
+		return 0, false, false
+	}
+	for s := 0; s < stick; s++ {
+		switch {
+		case l.Sticks[s].Hat && l.Sticks[stick].Hat:
+			index++
+		case !l.Sticks[s].Hat && !l.Sticks[stick].Hat:
+			index += l.Sticks[s].Axes
+		}
+	}
+	if l.Sticks[stick].Hat {
+		return index, true, axis < 2
+	}
+	return index + axis, false, axis < l.Sticks[stick].Axes
+}
+
+// ButtonEvent is generated when a button of the standard layout is pressed
+// or released.
+type ButtonEvent struct {
+	Gamepad   *Gamepad
+	Timestamp float64
+	Button    Button
+	Pressed   bool
+}
+
+// AxisEvent is generated when an axis of the standard layout moves.
+type AxisEvent struct {
+	Gamepad   *Gamepad
+	Timestamp float64
+	Axis      Axis
+	Value     float32
+}
+
+// Gamepad tracks the state of a joystick through a mapping.
+type Gamepad struct {
+	Joystick *allegro.Joystick
+	Mapping  *Mapping
+	Layout   Layout
+
+	// ButtonThreshold is how far an axis must be pushed to press a button
+	// mapped from it.
+	ButtonThreshold float32
+
+	rawButtons map[int]bool
+	rawAxes    map[int]float32
+	hats       map[int][2]float32
+
+	buttons [ButtonCount]bool
+	axes    [AxisCount]float32
+}
+
+// New() creates a gamepad for a joystick.
+func New(j *allegro.Joystick, m *Mapping) *Gamepad {
+	g := NewWithLayout(m, LayoutOf(j))
+	g.Joystick = j
+	return g
+}
+
+// NewWithLayout() creates a gamepad that isn't tied to a particular joystick,
+// and takes input from events for any joystick with the given layout. This is
+// mostly useful for testing.
+func NewWithLayout(m *Mapping, layout Layout) *Gamepad {
+	return &Gamepad{
+		Mapping:         m,
+		Layout:          layout,
+		ButtonThreshold: 0.5,
+		rawButtons:      make(map[int]bool),
+		rawAxes:         make(map[int]float32),
+		hats:            make(map[int][2]float32),
+	}
+}
+
+// Button() returns true if the button is held.
+func (g *Gamepad) Button(b Button) bool {
+	if b < 0 || b >= ButtonCount {
+		return false
+	}
+	return g.buttons[b]
+}
+
+// Axis() returns the position of the axis.
+func (g *Gamepad) Axis(a Axis) float32 {
+	if a < 0 || a >= AxisCount {
+		return 0
+	}
+	return g.axes[a]
+}
+
+// Handle() updates the gamepad from a joystick event, and returns a
+// ButtonEvent or AxisEvent for everything of the standard layout that changed
+// as a result. Events for other joysticks are ignored.
+func (g *Gamepad) Handle(e interface{}) []interface{} {
+	var timestamp float64
+	switch e := e.(type) {
+	case allegro.JoystickButtonDownEvent:
+		if !g.mine(e.Id()) {
+			return nil
+		}
+		timestamp = e.Timestamp()
+		g.rawButtons[e.Button()] = true
+	case allegro.JoystickButtonUpEvent:
+		if !g.mine(e.Id()) {
+			return nil
+		}
+		timestamp = e.Timestamp()
+		g.rawButtons[e.Button()] = false
+	case allegro.JoystickAxisEvent:
+		if !g.mine(e.Id()) {
+			return nil
+		}
+		timestamp = e.Timestamp()
+		index, hat, ok := g.Layout.locate(e.Stick(), e.Axis())
+		if !ok {
+			return nil
+		}
+		if hat {
+			h := g.hats[index]
+			h[e.Axis()] = e.Pos()
+			g.hats[index] = h
+		} else {
+			g.rawAxes[index] = e.Pos()
+		}
+	default:
+		return nil
+	}
+	return g.update(timestamp)
+}
+
+func (g *Gamepad) mine(j *allegro.Joystick) bool {
+	return g.Joystick == nil || g.Joystick == j
+}
+
+// value() returns the value of a raw input, from -1 to 1 for full axes and
+// 0 to 1 for everything else.
+func (g *Gamepad) value(src source) float32 {
+	switch src.kind {
+	case rawButton:
+		if g.rawButtons[src.index] {
+			return 1
+		}
+	case rawHat:
+		h := g.hats[src.index]
+		var mask int
+		if h[1] < 0 {
+			mask |= 1
+		}
+		if h[0] > 0 {
+			mask |= 2
+		}
+		if h[1] > 0 {
+			mask |= 4
+		}
+		if h[0] < 0 {
+			mask |= 8
+		}
+		if mask&src.hatMask != 0 {
+			return 1
+		}
+	case rawAxis:
+		v := g.rawAxes[src.index]
+		if src.invert {
+			v = -v
+		}
+		switch {
+		case src.half > 0 && v > 0:
+			return v
+		case src.half < 0 && v < 0:
+			return -v
+		case src.half == 0:
+			return v
+		}
+	}
+	return 0
+}
+
+// fullRange() returns true if the source produces values from -1 to 1.
+func (src source) fullRange() bool {
+	return src.kind == rawAxis && src.half == 0
+}
+
+func (g *Gamepad) axisValue(a Axis) float32 {
+	var total float32
+	trigger := a == LeftTrigger || a == RightTrigger
+	for _, src := range g.Mapping.axes[a] {
+		// Until a raw axis has reported in, its resting position isn't
+		// known, and a trigger would otherwise appear half pressed.
+		if _, ok := g.rawAxes[src.index]; src.kind == rawAxis && !ok {
+			continue
+		}
+		v := g.value(src)
+		if src.fullRange() && (trigger || src.outHalf != 0) {
+			v = (v + 1) / 2
+		}
+		if src.outHalf < 0 {
+			v = -v
+		}
+		total += v
+	}
+	if total > 1 {
+		return 1
+	} else if total < -1 {
+		return -1
+	}
+	return total
+}
+
+func (g *Gamepad) buttonValue(b Button) bool {
+	for _, src := range g.Mapping.buttons[b] {
+		if g.value(src) > g.ButtonThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Gamepad) update(timestamp float64) []interface{} {
+	if g.Mapping == nil {
+		return nil
+	}
+	var events []interface{}
+	for b := Button(0); b < ButtonCount; b++ {
+		if pressed := g.buttonValue(b); pressed != g.buttons[b] {
+			g.buttons[b] = pressed
+			events = append(events, ButtonEvent{Gamepad: g, Timestamp: timestamp, Button: b, Pressed: pressed})
+		}
+	}
+	for a := Axis(0); a < AxisCount; a++ {
+		if v := g.axisValue(a); v != g.axes[a] {
+			g.axes[a] = v
+			events = append(events, AxisEvent{Gamepad: g, Timestamp: timestamp, Axis: a, Value: v})
+		}
+	}
+	return events
+}
@@ -0,0 +1,123 @@
+package gamepad
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+const testDB = `# Game controller database used by the tests.
+030000005e0400008e02000000000000,Xbox 360 Controller,a:b0,b:b1,back:b6,dpdown:h0.4,dpleft:h0.8,dpright:h0.2,dpup:h0.1,guide:b8,leftshoulder:b4,leftstick:b9,lefttrigger:a2,leftx:a0,lefty:a1,rightshoulder:b5,rightstick:b10,righttrigger:a5,rightx:a3,righty:a4,start:b7,x:b2,y:b3,platform:Linux,
+030000005e0400008e02000000000000,Xbox 360 Controller (Windows),a:b1,platform:Windows,
+
+03000000ffff00000000000000000000,Retro Pad,a:b1,b:b0,start:b9,-leftx:b12,+leftx:b13,lefty:a1~,righttrigger:+a2,dpup:-a3,
+`
+
+// The layout of an Xbox 360 controller under Linux: the left stick, the left
+// trigger, the right stick, the right trigger and the D-pad.
+var xboxLayout = Layout{Sticks: []StickLayout{{Axes: 2}, {Axes: 1}, {Axes: 2}, {Axes: 1}, {Axes: 2, Hat: true}}}
+
+func loadTestDB(t *testing.T) *GameControllerDB {
+	db := NewGameControllerDB()
+	db.Platform = "Linux"
+	if err := db.Parse(strings.NewReader(testDB)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestLookup(t *testing.T) {
+	db := loadTestDB(t)
+	m, ok := db.Lookup("030000005E0400008E02000000000000", "")
+	if !ok || m.Name != "Xbox 360 Controller" {
+		t.Errorf("lookup by GUID found %v, want the Linux Xbox 360 mapping", m)
+	}
+	if m, ok := db.Lookup("", "Retro Pad"); !ok || m.GUID != "03000000ffff00000000000000000000" {
+		t.Errorf("lookup by name found %v, want Retro Pad", m)
+	}
+	if _, ok := db.Lookup("", "Xbox 360 Controller (Windows)"); ok {
+		t.Error("found a mapping for another platform")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{
+		"just a guid",
+		"0300,Pad,a:x0",
+		"0300,Pad,dpup:h0",
+		"0300,Pad,a",
+	} {
+		if _, err := ParseMapping(line); err == nil {
+			t.Errorf("'%s' parsed without error", line)
+		}
+	}
+}
+
+func feed(g *Gamepad, events ...interface{}) []interface{} {
+	var out []interface{}
+	for _, e := range events {
+		out = append(out, g.Handle(e)...)
+	}
+	return out
+}
+
+func TestXboxMapping(t *testing.T) {
+	m, _ := loadTestDB(t).Lookup("030000005e0400008e02000000000000", "")
+	g := NewWithLayout(m, xboxLayout)
+	var event allegro.Event
+
+	got := feed(g, event.SetJoystickButtonDown(1, nil, 0))
+	want := []interface{}{ButtonEvent{Gamepad: g, Timestamp: 1, Button: ButtonA, Pressed: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("button 0 down: %+v, want %+v", got, want)
+	}
+
+	got = feed(g, event.SetJoystickAxis(2, nil, 2, 1, 0.75))
+	want = []interface{}{AxisEvent{Gamepad: g, Timestamp: 2, Axis: RightStickY, Value: 0.75}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("right stick: %+v, want %+v", got, want)
+	}
+
+	got = feed(g, event.SetJoystickAxis(3, nil, 4, 1, -1))
+	want = []interface{}{ButtonEvent{Gamepad: g, Timestamp: 3, Button: ButtonDPadUp, Pressed: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hat up: %+v, want %+v", got, want)
+	}
+
+	// A trigger at rest produces no event; pulled all the way it reads 1.
+	if got = feed(g, event.SetJoystickAxis(4, nil, 3, 0, -1)); len(got) != 0 {
+		t.Errorf("trigger at rest: %+v, want nothing", got)
+	}
+	feed(g, event.SetJoystickAxis(5, nil, 3, 0, 1))
+	if v := g.Axis(RightTrigger); v != 1 {
+		t.Errorf("right trigger = %v, want 1", v)
+	}
+	if !g.Button(ButtonA) || !g.Button(ButtonDPadUp) || g.Button(ButtonB) {
+		t.Error("unexpected button state")
+	}
+}
+
+func TestHalfAxes(t *testing.T) {
+	m, _ := loadTestDB(t).Lookup("", "Retro Pad")
+	g := NewWithLayout(m, Layout{Sticks: []StickLayout{{Axes: 2}, {Axes: 2}}})
+	var event allegro.Event
+
+	feed(g, event.SetJoystickButtonDown(0, nil, 12))
+	if v := g.Axis(LeftStickX); v != -1 {
+		t.Errorf("left x with button 12 held = %v, want -1", v)
+	}
+	feed(g, event.SetJoystickAxis(0, nil, 0, 1, 0.5))
+	if v := g.Axis(LeftStickY); v != -0.5 {
+		t.Errorf("inverted left y = %v, want -0.5", v)
+	}
+	feed(g, event.SetJoystickAxis(0, nil, 1, 0, 0.8))
+	if v := g.Axis(RightTrigger); v != 0.8 {
+		t.Errorf("right trigger from half axis = %v, want 0.8", v)
+	}
+	feed(g, event.SetJoystickAxis(0, nil, 1, 1, -0.9))
+	if !g.Button(ButtonDPadUp) {
+		t.Error("D-pad up not pressed by negative half axis")
+	}
+}
@@ -0,0 +1,231 @@
+// Package gesture recognises taps, drags and pinches in a stream of touch
+// events.
+//
+// A Recognizer is fed every event read from a queue, and hands back the
+// gestures those events complete or update. It only looks at the typed touch
+// events, so it can be driven by synthetic events built with the Event.Set*
+// methods as easily as by a real touch screen:
+//
+//	r := gesture.NewRecognizer()
+//	for _, g := range r.Handle(e) {
+//		switch g := g.(type) {
+//		case gesture.Tap:
+//			selectAt(g.X, g.Y)
+//		case gesture.Pinch:
+//			camera.Zoom(g.Scale)
+//		}
+//	}
+package gesture
+
+import (
+	"math"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// Phase tells where a continuous gesture, such as a drag, is at.
+type Phase int
+
+const (
+	Began Phase = iota
+	Changed
+	Ended
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Began:
+		return "began"
+	case Changed:
+		return "changed"
+	case Ended:
+		return "ended"
+	}
+	return "unknown"
+}
+
+// Gesture is one of Tap, Drag or Pinch.
+type Gesture interface {
+	gesture()
+}
+
+// Tap is a single touch that was released quickly without moving much.
+type Tap struct {
+	Timestamp float64
+	X, Y      float32
+}
+
+// Drag is a single touch moving across the screen. Dx and Dy are the distance
+// moved since the last Drag for the same touch; for the one that begins the
+// drag they are the distance from where the touch started.
+type Drag struct {
+	Phase     Phase
+	Timestamp float64
+	X, Y      float32
+	Dx, Dy    float32
+}
+
+// Pinch is two touches moving towards or away from each other. X and Y are
+// the point halfway between them, and Scale is the distance between them
+// relative to when the pinch began.
+type Pinch struct {
+	Phase     Phase
+	Timestamp float64
+	X, Y      float32
+	Scale     float32
+}
+
+func (Tap) gesture()   {}
+func (Drag) gesture()  {}
+func (Pinch) gesture() {}
+
+type touch struct {
+	startX, startY float32
+	startTime      float64
+	x, y           float32
+	dragging       bool
+
+	// tainted touches were part of a pinch, so they can't become a tap.
+	tainted bool
+}
+
+// Recognizer turns touch events into gestures. Its fields can be changed to
+// tune how eager it is to recognise them.
+type Recognizer struct {
+	// TapTime is the longest a touch can be held down and still count as a
+	// tap, in seconds.
+	TapTime float64
+
+	// Slop is how far a touch can move, in pixels, before it is considered
+	// a drag rather than a tap.
+	Slop float32
+
+	touches  map[int]*touch
+	pinch    [2]int
+	pinching bool
+	distance float32
+}
+
+// NewRecognizer() creates a recognizer with reasonable defaults.
+func NewRecognizer() *Recognizer {
+	return &Recognizer{
+		TapTime: 0.3,
+		Slop:    10,
+		touches: make(map[int]*touch),
+	}
+}
+
+// Reset() forgets about any touches in progress, without ending their
+// gestures.
+func (r *Recognizer) Reset() {
+	r.touches = make(map[int]*touch)
+	r.pinching = false
+}
+
+// Handle() feeds an event to the recognizer and returns any gestures it
+// produced. Events other than touch events are ignored.
+func (r *Recognizer) Handle(e interface{}) []Gesture {
+	switch e := e.(type) {
+	case allegro.TouchBeginEvent:
+		return r.begin(e.Timestamp(), e.Id(), e.X(), e.Y())
+	case allegro.TouchMoveEvent:
+		return r.move(e.Timestamp(), e.Id(), e.X(), e.Y())
+	case allegro.TouchEndEvent:
+		return r.end(e.Timestamp(), e.Id(), e.X(), e.Y(), false)
+	case allegro.TouchCancelEvent:
+		return r.end(e.Timestamp(), e.Id(), e.X(), e.Y(), true)
+	}
+	return nil
+}
+
+func distance(a, b *touch) float32 {
+	return float32(math.Hypot(float64(a.x-b.x), float64(a.y-b.y)))
+}
+
+func (r *Recognizer) pinchGesture(phase Phase, timestamp float64) Pinch {
+	a, b := r.touches[r.pinch[0]], r.touches[r.pinch[1]]
+	scale := float32(1)
+	if r.distance > 0 {
+		scale = distance(a, b) / r.distance
+	}
+	return Pinch{
+		Phase:     phase,
+		Timestamp: timestamp,
+		X:         (a.x + b.x) / 2,
+		Y:         (a.y + b.y) / 2,
+		Scale:     scale,
+	}
+}
+
+func (r *Recognizer) begin(timestamp float64, id int, x, y float32) []Gesture {
+	if r.touches == nil {
+		r.touches = make(map[int]*touch)
+	}
+	if _, ok := r.touches[id]; ok || len(r.touches) >= 2 {
+		return nil
+	}
+	t := &touch{startX: x, startY: y, startTime: timestamp, x: x, y: y}
+	var gestures []Gesture
+	for other, o := range r.touches {
+		// A second finger turns whatever the first was doing into a pinch.
+		if o.dragging {
+			gestures = append(gestures, Drag{Phase: Ended, Timestamp: timestamp, X: o.x, Y: o.y})
+			o.dragging = false
+		}
+		o.tainted, t.tainted = true, true
+		r.touches[id] = t
+		r.pinch = [2]int{other, id}
+		r.pinching = true
+		r.distance = distance(o, t)
+		return append(gestures, r.pinchGesture(Began, timestamp))
+	}
+	r.touches[id] = t
+	return nil
+}
+
+func (r *Recognizer) move(timestamp float64, id int, x, y float32) []Gesture {
+	t, ok := r.touches[id]
+	if !ok {
+		return nil
+	}
+	lastX, lastY := t.x, t.y
+	t.x, t.y = x, y
+	if r.pinching {
+		return []Gesture{r.pinchGesture(Changed, timestamp)}
+	}
+	if t.dragging {
+		return []Gesture{Drag{Phase: Changed, Timestamp: timestamp, X: x, Y: y, Dx: x - lastX, Dy: y - lastY}}
+	}
+	dx, dy := x-t.startX, y-t.startY
+	if float32(math.Hypot(float64(dx), float64(dy))) > r.Slop {
+		t.dragging = true
+		return []Gesture{Drag{Phase: Began, Timestamp: timestamp, X: x, Y: y, Dx: dx, Dy: dy}}
+	}
+	return nil
+}
+
+func (r *Recognizer) end(timestamp float64, id int, x, y float32, cancelled bool) []Gesture {
+	t, ok := r.touches[id]
+	if !ok {
+		return nil
+	}
+	t.x, t.y = x, y
+	var gestures []Gesture
+	switch {
+	case r.pinching:
+		gestures = append(gestures, r.pinchGesture(Ended, timestamp))
+		r.pinching = false
+	case t.dragging:
+		gestures = append(gestures, Drag{Phase: Ended, Timestamp: timestamp, X: x, Y: y})
+	case !cancelled && !t.tainted && timestamp-t.startTime <= r.TapTime:
+		gestures = append(gestures, Tap{Timestamp: timestamp, X: t.startX, Y: t.startY})
+	}
+	delete(r.touches, id)
+
+	// Whatever touch is left over has to start moving afresh before it
+	// becomes a drag.
+	for _, o := range r.touches {
+		o.startX, o.startY = o.x, o.y
+	}
+	return gestures
+}
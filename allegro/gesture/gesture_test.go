@@ -0,0 +1,90 @@
+package gesture
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+type step struct {
+	kind    string
+	t       float64
+	id      int
+	x, y    float32
+	gesture []Gesture
+}
+
+func run(t *testing.T, steps []step) {
+	r := NewRecognizer()
+	var event allegro.Event
+	for i, s := range steps {
+		var e interface{}
+		switch s.kind {
+		case "begin":
+			e = event.SetTouchBegin(s.t, s.id, s.x, s.y, 0, 0, s.id == 0, nil)
+		case "move":
+			e = event.SetTouchMove(s.t, s.id, s.x, s.y, 0, 0, s.id == 0, nil)
+		case "end":
+			e = event.SetTouchEnd(s.t, s.id, s.x, s.y, 0, 0, s.id == 0, nil)
+		case "cancel":
+			e = event.SetTouchCancel(s.t, s.id, s.x, s.y, 0, 0, s.id == 0, nil)
+		}
+		if got := r.Handle(e); !reflect.DeepEqual(got, s.gesture) {
+			t.Errorf("step %d (%s %d): got %+v, want %+v", i, s.kind, s.id, got, s.gesture)
+		}
+	}
+}
+
+func TestTap(t *testing.T) {
+	run(t, []step{
+		{"begin", 0, 0, 100, 100, nil},
+		{"move", 0.05, 0, 103, 101, nil},
+		{"end", 0.1, 0, 103, 101, []Gesture{Tap{Timestamp: 0.1, X: 100, Y: 100}}},
+	})
+}
+
+func TestSlowTapIsIgnored(t *testing.T) {
+	run(t, []step{
+		{"begin", 0, 0, 100, 100, nil},
+		{"end", 1, 0, 100, 100, nil},
+	})
+}
+
+func TestDrag(t *testing.T) {
+	run(t, []step{
+		{"begin", 0, 0, 100, 100, nil},
+		{"move", 0.1, 0, 120, 100, []Gesture{Drag{Phase: Began, Timestamp: 0.1, X: 120, Y: 100, Dx: 20}}},
+		{"move", 0.2, 0, 125, 90, []Gesture{Drag{Phase: Changed, Timestamp: 0.2, X: 125, Y: 90, Dx: 5, Dy: -10}}},
+		{"end", 0.3, 0, 125, 90, []Gesture{Drag{Phase: Ended, Timestamp: 0.3, X: 125, Y: 90}}},
+	})
+}
+
+func TestCancelledTouch(t *testing.T) {
+	run(t, []step{
+		{"begin", 0, 0, 100, 100, nil},
+		{"cancel", 0.1, 0, 100, 100, nil},
+	})
+}
+
+func TestPinch(t *testing.T) {
+	run(t, []step{
+		{"begin", 0, 0, 100, 100, nil},
+		{"begin", 0.05, 1, 200, 100, []Gesture{Pinch{Phase: Began, Timestamp: 0.05, X: 150, Y: 100, Scale: 1}}},
+		{"move", 0.1, 1, 300, 100, []Gesture{Pinch{Phase: Changed, Timestamp: 0.1, X: 200, Y: 100, Scale: 2}}},
+		{"move", 0.15, 0, 250, 100, []Gesture{Pinch{Phase: Changed, Timestamp: 0.15, X: 275, Y: 100, Scale: 0.5}}},
+		{"end", 0.2, 1, 300, 100, []Gesture{Pinch{Phase: Ended, Timestamp: 0.2, X: 275, Y: 100, Scale: 0.5}}},
+		{"end", 0.25, 0, 250, 100, nil},
+	})
+}
+
+func TestDragTurnsIntoPinch(t *testing.T) {
+	run(t, []step{
+		{"begin", 0, 0, 0, 0, nil},
+		{"move", 0.1, 0, 50, 0, []Gesture{Drag{Phase: Began, Timestamp: 0.1, X: 50, Y: 0, Dx: 50}}},
+		{"begin", 0.2, 1, 150, 0, []Gesture{
+			Drag{Phase: Ended, Timestamp: 0.2, X: 50, Y: 0},
+			Pinch{Phase: Began, Timestamp: 0.2, X: 100, Y: 0, Scale: 1},
+		}},
+	})
+}
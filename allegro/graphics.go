@@ -4,10 +4,10 @@ package allegro
 import "C"
 import (
 	"errors"
-	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"runtime"
 )
 
 const rgbaMAX = 0xFFFF
@@ -159,7 +159,7 @@ func LoadBitmap(filename string) (*Bitmap, error) {
 	defer freeString(filename_)
 	bmp := C.al_load_bitmap(filename_)
 	if bmp == nil {
-		return nil, fmt.Errorf("failed to load bitmap at '%s'", filename)
+		return nil, LoadError("", "load bitmap", filename)
 	}
 	bitmap := (*Bitmap)(bmp)
 	//runtime.SetFinalizer(bitmap, bitmap.Destroy)
@@ -284,6 +284,7 @@ func CurrentDisplay() *Display {
 
 // Same as al_set_target_bitmap(al_get_backbuffer(display));
 func SetTargetBackbuffer(d *Display) {
+	checkDisplayThread("SetTargetBackbuffer", d)
 	C.al_set_target_backbuffer((*C.ALLEGRO_DISPLAY)(d))
 }
 
@@ -296,9 +297,12 @@ func SetTargetBackbuffer(d *Display) {
 func (bmp *Bitmap) Save(filename string) error {
 	filename_ := C.CString(filename)
 	defer freeString(filename_)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	ok := C.al_save_bitmap(filename_, (*C.ALLEGRO_BITMAP)(bmp))
 	if !ok {
-		return fmt.Errorf("failed to save bitmap at '%s'", filename)
+		return NewError("", "save bitmap", filename, nil)
 	}
 	return nil
 }
@@ -619,7 +623,7 @@ func (bmp *Bitmap) CreateSubBitmap(x, y, w, h int) (*Bitmap, error) {
 	sub := C.al_create_sub_bitmap((*C.ALLEGRO_BITMAP)(bmp),
 		C.int(x), C.int(y), C.int(w), C.int(h))
 	if sub == nil {
-		return nil, errors.New("failed to create sub-bitmap")
+		return nil, &Error{Op: "create sub-bitmap"}
 	}
 	return (*Bitmap)(sub), nil
 }
@@ -651,7 +655,7 @@ func (bmp *Bitmap) Clone() (*Bitmap, error) {
 	}
 	clone := C.al_clone_bitmap((*C.ALLEGRO_BITMAP)(bmp))
 	if clone == nil {
-		return nil, errors.New("failed to clone bitmap")
+		return nil, &Error{Op: "clone bitmap"}
 	}
 	return (*Bitmap)(clone), nil
 }
@@ -838,9 +842,12 @@ func (format PixelFormat) PixelFormatBits() int {
 func (f *File) LoadBitmap(ident string) (*Bitmap, error) {
 	ident_ := C.CString(ident)
 	defer freeString(ident_)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	bmp := C.al_load_bitmap_f((*C.ALLEGRO_FILE)(f), ident_)
 	if bmp == nil {
-		return nil, errors.New("failed to load bitmap from file")
+		return nil, LoadFileError("", "load bitmap", "")
 	}
 	return (*Bitmap)(bmp), nil
 }
@@ -851,9 +858,12 @@ func (f *File) LoadBitmap(ident string) (*Bitmap, error) {
 func (f *File) SaveBitmap(ident string, bmp *Bitmap) error {
 	ident_ := C.CString(ident)
 	defer freeString(ident_)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
 	ok := bool(C.al_save_bitmap_f((*C.ALLEGRO_FILE)(f), ident_, (*C.ALLEGRO_BITMAP)(bmp)))
 	if !ok {
-		return errors.New("failed to save bitmap to file")
+		return NewError("", "save bitmap", "", nil)
 	}
 	return nil
 }
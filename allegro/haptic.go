@@ -0,0 +1,367 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+/*
+static void set_haptic_envelope(ALLEGRO_HAPTIC_ENVELOPE *env, double attack_length, double attack_level, double fade_length, double fade_level) {
+	env->attack_length = attack_length;
+	env->attack_level = attack_level;
+	env->fade_length = fade_length;
+	env->fade_level = fade_level;
+}
+
+static void set_haptic_rumble(ALLEGRO_HAPTIC_EFFECT *e, double strong, double weak) {
+	e->data.rumble.strong_magnitude = strong;
+	e->data.rumble.weak_magnitude = weak;
+}
+
+static ALLEGRO_HAPTIC_ENVELOPE *set_haptic_periodic(ALLEGRO_HAPTIC_EFFECT *e, int waveform, double period, double magnitude, double offset, double phase) {
+	e->data.periodic.waveform = waveform;
+	e->data.periodic.period = period;
+	e->data.periodic.magnitude = magnitude;
+	e->data.periodic.offset = offset;
+	e->data.periodic.phase = phase;
+	e->data.periodic.custom_len = 0;
+	e->data.periodic.custom_data = NULL;
+	return &e->data.periodic.envelope;
+}
+
+static ALLEGRO_HAPTIC_ENVELOPE *set_haptic_constant(ALLEGRO_HAPTIC_EFFECT *e, double level) {
+	e->data.constant.level = level;
+	return &e->data.constant.envelope;
+}
+
+static ALLEGRO_HAPTIC_ENVELOPE *set_haptic_ramp(ALLEGRO_HAPTIC_EFFECT *e, double start_level, double end_level) {
+	e->data.ramp.start_level = start_level;
+	e->data.ramp.end_level = end_level;
+	return &e->data.ramp.envelope;
+}
+*/
+import "C"
+
+type Haptic C.ALLEGRO_HAPTIC
+
+// EffectID identifies an effect that has been uploaded to a haptic device.
+type EffectID C.ALLEGRO_HAPTIC_EFFECT_ID
+
+// HapticFlags describes the capabilities of a haptic device, and doubles as
+// the type of a HapticEffect.
+type HapticFlags int
+
+const (
+	HAPTIC_RUMBLE     HapticFlags = C.ALLEGRO_HAPTIC_RUMBLE
+	HAPTIC_PERIODIC               = C.ALLEGRO_HAPTIC_PERIODIC
+	HAPTIC_CONSTANT               = C.ALLEGRO_HAPTIC_CONSTANT
+	HAPTIC_SPRING                 = C.ALLEGRO_HAPTIC_SPRING
+	HAPTIC_FRICTION               = C.ALLEGRO_HAPTIC_FRICTION
+	HAPTIC_DAMPER                 = C.ALLEGRO_HAPTIC_DAMPER
+	HAPTIC_INERTIA                = C.ALLEGRO_HAPTIC_INERTIA
+	HAPTIC_RAMP                   = C.ALLEGRO_HAPTIC_RAMP
+	HAPTIC_SQUARE                 = C.ALLEGRO_HAPTIC_SQUARE
+	HAPTIC_TRIANGLE               = C.ALLEGRO_HAPTIC_TRIANGLE
+	HAPTIC_SINE                   = C.ALLEGRO_HAPTIC_SINE
+	HAPTIC_SAW_UP                 = C.ALLEGRO_HAPTIC_SAW_UP
+	HAPTIC_SAW_DOWN               = C.ALLEGRO_HAPTIC_SAW_DOWN
+	HAPTIC_CUSTOM                 = C.ALLEGRO_HAPTIC_CUSTOM
+	HAPTIC_GAIN                   = C.ALLEGRO_HAPTIC_GAIN
+	HAPTIC_ANGLE                  = C.ALLEGRO_HAPTIC_ANGLE
+	HAPTIC_RADIUS                 = C.ALLEGRO_HAPTIC_RADIUS
+	HAPTIC_AZIMUTH                = C.ALLEGRO_HAPTIC_AZIMUTH
+	HAPTIC_AUTOCENTER             = C.ALLEGRO_HAPTIC_AUTOCENTER
+)
+
+// HapticEnvelope shapes the start and end of periodic, constant and ramp
+// effects.
+type HapticEnvelope struct {
+	AttackLength, AttackLevel float64
+	FadeLength, FadeLevel     float64
+}
+
+type HapticRumble struct {
+	StrongMagnitude, WeakMagnitude float64
+}
+
+type HapticPeriodic struct {
+	// Waveform is one of HAPTIC_SQUARE, HAPTIC_TRIANGLE, HAPTIC_SINE,
+	// HAPTIC_SAW_UP or HAPTIC_SAW_DOWN.
+	Waveform                         HapticFlags
+	Period, Magnitude, Offset, Phase float64
+	Envelope                         HapticEnvelope
+}
+
+type HapticConstant struct {
+	Level    float64
+	Envelope HapticEnvelope
+}
+
+type HapticRamp struct {
+	StartLevel, EndLevel float64
+	Envelope             HapticEnvelope
+}
+
+// HapticEffect describes a haptic effect. Type selects which one of Rumble,
+// Periodic, Constant or Ramp is used; the others are ignored.
+type HapticEffect struct {
+	Type HapticFlags
+
+	// The direction the effect comes from. Angle and Azimuth are in
+	// radians, and Radius goes from 0 to 1.
+	Angle, Radius, Azimuth float64
+
+	// How long the effect lasts, and how long to wait before starting it,
+	// in seconds.
+	Length, Delay float64
+
+	// An optional button that triggers the effect, and the minimum time
+	// between two triggers.
+	TriggerButton   int
+	TriggerInterval float64
+
+	Rumble   HapticRumble
+	Periodic HapticPeriodic
+	Constant HapticConstant
+	Ramp     HapticRamp
+}
+
+func (env *HapticEnvelope) toC(c *C.ALLEGRO_HAPTIC_ENVELOPE) {
+	C.set_haptic_envelope(c, C.double(env.AttackLength), C.double(env.AttackLevel), C.double(env.FadeLength), C.double(env.FadeLevel))
+}
+
+func (effect *HapticEffect) toC() *C.ALLEGRO_HAPTIC_EFFECT {
+	var c C.ALLEGRO_HAPTIC_EFFECT
+	c._type = C.int(effect.Type)
+	c.direction.angle = C.double(effect.Angle)
+	c.direction.radius = C.double(effect.Radius)
+	c.direction.azimuth = C.double(effect.Azimuth)
+	c.replay.length = C.double(effect.Length)
+	c.replay.delay = C.double(effect.Delay)
+	c.trigger.button = C.int(effect.TriggerButton)
+	c.trigger.interval = C.double(effect.TriggerInterval)
+	switch effect.Type {
+	case HAPTIC_RUMBLE:
+		C.set_haptic_rumble(&c, C.double(effect.Rumble.StrongMagnitude), C.double(effect.Rumble.WeakMagnitude))
+	case HAPTIC_PERIODIC:
+		p := &effect.Periodic
+		p.Envelope.toC(C.set_haptic_periodic(&c, C.int(p.Waveform), C.double(p.Period), C.double(p.Magnitude), C.double(p.Offset), C.double(p.Phase)))
+	case HAPTIC_CONSTANT:
+		effect.Constant.Envelope.toC(C.set_haptic_constant(&c, C.double(effect.Constant.Level)))
+	case HAPTIC_RAMP:
+		effect.Ramp.Envelope.toC(C.set_haptic_ramp(&c, C.double(effect.Ramp.StartLevel), C.double(effect.Ramp.EndLevel)))
+	}
+	return &c
+}
+
+// Installs the haptic (force feedback) device subsystem. This must be called
+// before using any other haptic-related functions.
+func InstallHaptic() error {
+	if !bool(C.al_install_haptic()) {
+		return &Error{Op: "install haptic"}
+	}
+	return nil
+}
+
+// Uninstalls the haptic device subsystem. This is useful since on some
+// platforms haptic effects are bound to the active display.
+func UninstallHaptic() {
+	C.al_uninstall_haptic()
+}
+
+// Returns true if the haptic device subsystem is installed, false if not.
+func IsHapticInstalled() bool {
+	return bool(C.al_is_haptic_installed())
+}
+
+// Returns true if haptic effects are supported by the joystick, false if not.
+func (j *Joystick) IsHaptic() bool {
+	return bool(C.al_is_joystick_haptic((*C.ALLEGRO_JOYSTICK)(j)))
+}
+
+// Returns true if haptic effects are supported by the device on which the
+// display is shown, false if not.
+func (d *Display) IsHaptic() bool {
+	return bool(C.al_is_display_haptic((*C.ALLEGRO_DISPLAY)(d)))
+}
+
+// Returns true if haptic effects are supported by the keyboard, false if not.
+func (k *Keyboard) IsHaptic() bool {
+	return bool(C.al_is_keyboard_haptic((*C.ALLEGRO_KEYBOARD)(k)))
+}
+
+// Returns true if haptic effects are supported by the mouse, false if not.
+func (m *Mouse) IsHaptic() bool {
+	return bool(C.al_is_mouse_haptic((*C.ALLEGRO_MOUSE)(m)))
+}
+
+// Returns true if haptic effects are supported by the touch input device,
+// false if not.
+func (t *TouchInput) IsHaptic() bool {
+	return bool(C.al_is_touch_input_haptic((*C.ALLEGRO_TOUCH_INPUT)(t)))
+}
+
+func newHaptic(h *C.ALLEGRO_HAPTIC, what string) (*Haptic, error) {
+	if h == nil {
+		return nil, &Error{Op: "get haptic device from " + what}
+	}
+	return (*Haptic)(h), nil
+}
+
+// Returns the haptic device of the joystick, or an error if it doesn't support
+// haptic effects.
+func (j *Joystick) Haptic() (*Haptic, error) {
+	return newHaptic(C.al_get_haptic_from_joystick((*C.ALLEGRO_JOYSTICK)(j)), "joystick")
+}
+
+// Returns the haptic device of the device on which the display is shown, or
+// an error if it doesn't support haptic effects.
+func (d *Display) Haptic() (*Haptic, error) {
+	return newHaptic(C.al_get_haptic_from_display((*C.ALLEGRO_DISPLAY)(d)), "display")
+}
+
+// Returns the haptic device of the keyboard, or an error if it doesn't support
+// haptic effects.
+func (k *Keyboard) Haptic() (*Haptic, error) {
+	return newHaptic(C.al_get_haptic_from_keyboard((*C.ALLEGRO_KEYBOARD)(k)), "keyboard")
+}
+
+// Returns the haptic device of the mouse, or an error if it doesn't support
+// haptic effects.
+func (m *Mouse) Haptic() (*Haptic, error) {
+	return newHaptic(C.al_get_haptic_from_mouse((*C.ALLEGRO_MOUSE)(m)), "mouse")
+}
+
+// Returns the haptic device of the touch input device, or an error if it
+// doesn't support haptic effects.
+func (t *TouchInput) Haptic() (*Haptic, error) {
+	return newHaptic(C.al_get_haptic_from_touch_input((*C.ALLEGRO_TOUCH_INPUT)(t)), "touch input")
+}
+
+// Releases the haptic device and its resources when it's not needed anymore.
+// Any effects uploaded to it are released too.
+func (h *Haptic) Release() error {
+	if !bool(C.al_release_haptic((*C.ALLEGRO_HAPTIC)(h))) {
+		return &Error{Op: "release haptic device"}
+	}
+	return nil
+}
+
+// Returns true if the haptic device can currently be used, false if not.
+func (h *Haptic) Active() bool {
+	return bool(C.al_is_haptic_active((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns a set of flags describing the haptic capabilities of the device.
+func (h *Haptic) Capabilities() HapticFlags {
+	return HapticFlags(C.al_get_haptic_capabilities((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns true if the haptic device supports all of the given capabilities.
+func (h *Haptic) IsCapable(query HapticFlags) bool {
+	return bool(C.al_is_haptic_capable((*C.ALLEGRO_HAPTIC)(h), C.int(query)))
+}
+
+// Sets the gain of the haptic device if supported. Gain is much like volume
+// for sound, it is as if every effect's intensity is multiplied by it. Gain
+// is a value between 0.0 and 1.0.
+func (h *Haptic) SetGain(gain float64) error {
+	if !bool(C.al_set_haptic_gain((*C.ALLEGRO_HAPTIC)(h), C.double(gain))) {
+		return &Error{Op: "set haptic gain"}
+	}
+	return nil
+}
+
+// Returns the current gain of the device.
+func (h *Haptic) Gain() float64 {
+	return float64(C.al_get_haptic_gain((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Turns on or off the automatic centering feature of the haptic device if
+// supported. Intensity is a value between 0.0 and 1.0.
+func (h *Haptic) SetAutocenter(intensity float64) error {
+	if !bool(C.al_set_haptic_autocenter((*C.ALLEGRO_HAPTIC)(h), C.double(intensity))) {
+		return &Error{Op: "set haptic autocenter"}
+	}
+	return nil
+}
+
+// Returns the current automatic centering intensity of the device.
+func (h *Haptic) Autocenter() float64 {
+	return float64(C.al_get_haptic_autocenter((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns the maximum amount of haptic effects that can be uploaded to the
+// device.
+func (h *Haptic) MaxEffects() int {
+	return int(C.al_get_max_haptic_effects((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns true if the haptic device can play the haptic effect as given,
+// false if not.
+func (h *Haptic) IsEffectOK(effect *HapticEffect) bool {
+	return bool(C.al_is_haptic_effect_ok((*C.ALLEGRO_HAPTIC)(h), effect.toC()))
+}
+
+// Uploads the haptic effect to the device, returning an id that can be used
+// to play it.
+func (h *Haptic) Upload(effect *HapticEffect) (*EffectID, error) {
+	id := new(EffectID)
+	if !bool(C.al_upload_haptic_effect((*C.ALLEGRO_HAPTIC)(h), effect.toC(), (*C.ALLEGRO_HAPTIC_EFFECT_ID)(id))) {
+		return nil, &Error{Op: "upload haptic effect"}
+	}
+	return id, nil
+}
+
+// Uploads the haptic effect to the device and starts playback immediately,
+// repeating it loop times.
+func (h *Haptic) UploadAndPlay(effect *HapticEffect, loop int) (*EffectID, error) {
+	id := new(EffectID)
+	if !bool(C.al_upload_and_play_haptic_effect((*C.ALLEGRO_HAPTIC)(h), effect.toC(), (*C.ALLEGRO_HAPTIC_EFFECT_ID)(id), C.int(loop))) {
+		return nil, &Error{Op: "upload and play haptic effect"}
+	}
+	return id, nil
+}
+
+// Uploads a simple rumble effect to the haptic device and starts playback
+// immediately. Intensity is between 0.0 and 1.0, and duration is in seconds.
+func (h *Haptic) Rumble(intensity, duration float64) (*EffectID, error) {
+	id := new(EffectID)
+	if !bool(C.al_rumble_haptic((*C.ALLEGRO_HAPTIC)(h), C.double(intensity), C.double(duration), (*C.ALLEGRO_HAPTIC_EFFECT_ID)(id))) {
+		return nil, &Error{Op: "rumble haptic device"}
+	}
+	return id, nil
+}
+
+// Plays back a previously uploaded haptic effect, loop times.
+func (id *EffectID) Play(loop int) error {
+	if !bool(C.al_play_haptic_effect((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id), C.int(loop))) {
+		return &Error{Op: "play haptic effect"}
+	}
+	return nil
+}
+
+// Stops playing a previously uploaded haptic effect.
+func (id *EffectID) Stop() error {
+	if !bool(C.al_stop_haptic_effect((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id))) {
+		return &Error{Op: "stop haptic effect"}
+	}
+	return nil
+}
+
+// Returns true if the haptic effect is currently playing.
+func (id *EffectID) IsPlaying() bool {
+	return bool(C.al_is_haptic_effect_playing((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id)))
+}
+
+// Releases a previously uploaded haptic effect from the device it has been
+// uploaded to, allowing for other effects to be uploaded.
+func (id *EffectID) Release() error {
+	if !bool(C.al_release_haptic_effect((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id))) {
+		return &Error{Op: "release haptic effect"}
+	}
+	return nil
+}
+
+// Returns the estimated duration in seconds of a single loop of the given
+// haptic effect.
+func (effect *HapticEffect) Duration() float64 {
+	return float64(C.al_get_haptic_effect_duration(effect.toC()))
+}
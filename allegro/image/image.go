@@ -5,7 +5,7 @@ package image
 // #include <allegro5/allegro_image.h>
 import "C"
 import (
-	"errors"
+	"github.com/phrasz/nag/allegro"
 )
 
 // Initializes the image addon. This registers bitmap format handlers for
@@ -13,7 +13,7 @@ import (
 func Install() error {
 	ok := bool(C.al_init_image_addon())
 	if !ok {
-		return errors.New("failed to initialize image addon")
+		return &allegro.Error{Op: "install", Addon: "image"}
 	}
 	return nil
 }
@@ -0,0 +1,263 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// Device is the kind of physical input an Input refers to.
+type Device int
+
+const (
+	Key Device = iota + 1
+	MouseButton
+	JoystickButton
+	JoystickAxis
+)
+
+// Input is a single physical key, button or half of a joystick axis. Input
+// from every joystick is merged, so joystick inputs don't say which joystick
+// they belong to.
+type Input struct {
+	Device Device
+	Code   int // key code, mouse button, joystick button or axis
+
+	// Stick and Dir are only used by joystick axes. Dir is +1 or -1, and
+	// selects which half of the axis counts as held.
+	Stick int
+	Dir   int
+}
+
+// Binding maps one or more inputs onto an action. When it has several inputs
+// they form a chord, which is only held while all of them are. The modifiers
+// must be held too; other modifiers don't get in the way.
+type Binding struct {
+	Inputs    []Input
+	Modifiers allegro.KeyModifier
+}
+
+// AxisBinding maps either an analog joystick axis, or a pair of digital
+// bindings, onto an axis.
+type AxisBinding struct {
+	Analog      bool
+	Stick, Axis int
+
+	Negative, Positive Binding
+}
+
+// KeyBinding() returns a binding for a single key.
+func KeyBinding(code allegro.KeyCode) Binding {
+	return Binding{Inputs: []Input{{Device: Key, Code: int(code)}}}
+}
+
+// MouseButtonBinding() returns a binding for a single mouse button. The
+// first button is 1.
+func MouseButtonBinding(button uint) Binding {
+	return Binding{Inputs: []Input{{Device: MouseButton, Code: int(button)}}}
+}
+
+// JoystickButtonBinding() returns a binding for a single joystick button.
+func JoystickButtonBinding(button int) Binding {
+	return Binding{Inputs: []Input{{Device: JoystickButton, Code: button}}}
+}
+
+// JoystickAxisBinding() returns a binding for one half of a joystick axis,
+// which is held while the axis is pushed past the dead zone in the direction
+// given by dir (+1 or -1).
+func JoystickAxisBinding(stick, axis, dir int) Binding {
+	if dir < 0 {
+		dir = -1
+	} else {
+		dir = 1
+	}
+	return Binding{Inputs: []Input{{Device: JoystickAxis, Stick: stick, Code: axis, Dir: dir}}}
+}
+
+// Chord() combines several bindings into one that is only held while all of
+// them are.
+func Chord(bindings ...Binding) Binding {
+	var c Binding
+	for _, b := range bindings {
+		c.Inputs = append(c.Inputs, b.Inputs...)
+		c.Modifiers |= b.Modifiers
+	}
+	return c
+}
+
+// With() returns a copy of the binding that also requires the modifiers.
+func (b Binding) With(mods allegro.KeyModifier) Binding {
+	b.Inputs = append([]Input(nil), b.Inputs...)
+	b.Modifiers |= mods
+	return b
+}
+
+// AnalogAxis() returns an axis binding for an analog joystick axis.
+func AnalogAxis(stick, axis int) AxisBinding {
+	return AxisBinding{Analog: true, Stick: stick, Axis: axis}
+}
+
+// DigitalAxis() returns an axis binding that is -1 while negative is held,
+// and +1 while positive is.
+func DigitalAxis(negative, positive Binding) AxisBinding {
+	return AxisBinding{Negative: negative, Positive: positive}
+}
+
+/* -- Text format -- */
+
+// Bindings are saved to configs as text, e.g. "ctrl+key:S", "mouse:1",
+// "joy:button:3", "joy:axis:0:1:-" or "key:A+key:B" for a chord. Several
+// bindings for the same action are separated by commas. A digital axis binding
+// is written as its negative and positive bindings separated by a slash, and
+// an analog one as "joy:axis:0:1".
+
+var modifierNames = []struct {
+	name string
+	mod  allegro.KeyModifier
+}{
+	{"shift", allegro.KEYMOD_SHIFT},
+	{"ctrl", allegro.KEYMOD_CTRL},
+	{"alt", allegro.KEYMOD_ALT},
+	{"altgr", allegro.KEYMOD_ALTGR},
+	{"lwin", allegro.KEYMOD_LWIN},
+	{"rwin", allegro.KEYMOD_RWIN},
+	{"menu", allegro.KEYMOD_MENU},
+	{"command", allegro.KEYMOD_COMMAND},
+}
+
+func (in Input) String() string {
+	switch in.Device {
+	case Key:
+		if name := allegro.KeyCode(in.Code).StableName(); name != "" {
+			return "key:" + name
+		}
+		return fmt.Sprintf("key:%d", in.Code)
+	case MouseButton:
+		return fmt.Sprintf("mouse:%d", in.Code)
+	case JoystickButton:
+		return fmt.Sprintf("joy:button:%d", in.Code)
+	case JoystickAxis:
+		dir := "+"
+		if in.Dir < 0 {
+			dir = "-"
+		}
+		return fmt.Sprintf("joy:axis:%d:%d:%s", in.Stick, in.Code, dir)
+	}
+	return "unknown"
+}
+
+func (b Binding) String() string {
+	var parts []string
+	for _, m := range modifierNames {
+		if b.Modifiers&m.mod != 0 {
+			parts = append(parts, m.name)
+		}
+	}
+	for _, in := range b.Inputs {
+		parts = append(parts, in.String())
+	}
+	return strings.Join(parts, "+")
+}
+
+func (a AxisBinding) String() string {
+	if a.Analog {
+		return fmt.Sprintf("joy:axis:%d:%d", a.Stick, a.Axis)
+	}
+	return a.Negative.String() + " / " + a.Positive.String()
+}
+
+func atoi(s, what string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s'", what, s)
+	}
+	return n, nil
+}
+
+// ParseInput() parses a single input, as written by Input.String().
+func ParseInput(s string) (Input, error) {
+	fields := strings.Split(strings.TrimSpace(s), ":")
+	switch {
+	case fields[0] == "key" && len(fields) == 2:
+		if k, ok := allegro.KeyCodeByName(fields[1]); ok {
+			return Input{Device: Key, Code: int(k)}, nil
+		}
+		code, err := atoi(fields[1], "key")
+		return Input{Device: Key, Code: code}, err
+	case fields[0] == "mouse" && len(fields) == 2:
+		button, err := atoi(fields[1], "mouse button")
+		return Input{Device: MouseButton, Code: button}, err
+	case fields[0] == "joy" && len(fields) == 3 && fields[1] == "button":
+		button, err := atoi(fields[2], "joystick button")
+		return Input{Device: JoystickButton, Code: button}, err
+	case fields[0] == "joy" && len(fields) == 5 && fields[1] == "axis":
+		stick, err := atoi(fields[2], "joystick stick")
+		if err != nil {
+			return Input{}, err
+		}
+		axis, err := atoi(fields[3], "joystick axis")
+		if err != nil {
+			return Input{}, err
+		}
+		switch fields[4] {
+		case "+":
+			return Input{Device: JoystickAxis, Stick: stick, Code: axis, Dir: 1}, nil
+		case "-":
+			return Input{Device: JoystickAxis, Stick: stick, Code: axis, Dir: -1}, nil
+		}
+		return Input{}, fmt.Errorf("invalid joystick axis direction '%s'", fields[4])
+	}
+	return Input{}, fmt.Errorf("invalid input '%s'", s)
+}
+
+// ParseBinding() parses a binding, as written by Binding.String().
+func ParseBinding(s string) (Binding, error) {
+	var b Binding
+parts:
+	for _, part := range strings.Split(s, "+") {
+		part = strings.TrimSpace(part)
+		for _, m := range modifierNames {
+			if part == m.name {
+				b.Modifiers |= m.mod
+				continue parts
+			}
+		}
+		in, err := ParseInput(part)
+		if err != nil {
+			return b, err
+		}
+		b.Inputs = append(b.Inputs, in)
+	}
+	if len(b.Inputs) == 0 {
+		return b, fmt.Errorf("binding '%s' has no inputs", s)
+	}
+	return b, nil
+}
+
+// ParseAxisBinding() parses an axis binding, as written by
+// AxisBinding.String().
+func ParseAxisBinding(s string) (AxisBinding, error) {
+	if neg, pos, ok := strings.Cut(s, "/"); ok {
+		var (
+			a   AxisBinding
+			err error
+		)
+		if a.Negative, err = ParseBinding(neg); err != nil {
+			return a, err
+		}
+		a.Positive, err = ParseBinding(pos)
+		return a, err
+	}
+	fields := strings.Split(strings.TrimSpace(s), ":")
+	if len(fields) != 4 || fields[0] != "joy" || fields[1] != "axis" {
+		return AxisBinding{}, fmt.Errorf("invalid axis binding '%s'", s)
+	}
+	stick, err := atoi(fields[2], "joystick stick")
+	if err != nil {
+		return AxisBinding{}, err
+	}
+	axis, err := atoi(fields[3], "joystick axis")
+	return AnalogAxis(stick, axis), err
+}
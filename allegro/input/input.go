@@ -0,0 +1,502 @@
+// Package input maps named actions and axes onto keys, mouse buttons and
+// joysticks, so that game code can ask whether "jump" was pressed instead of
+// checking for the space bar.
+//
+// A Map is fed either events, through Handle(), or polled device states,
+// through Poll(), and then Update() is called once per frame to work out
+// which actions were pressed, released or held during it:
+//
+//	m := input.NewMap()
+//	m.BindAction("jump", input.KeyBinding(allegro.KEY_SPACE), input.JoystickButtonBinding(0))
+//	m.BindAxis("move", input.DigitalAxis(input.KeyBinding(allegro.KEY_LEFT), input.KeyBinding(allegro.KEY_RIGHT)), input.AnalogAxis(0, 0))
+//
+//	// In the main loop:
+//	m.Handle(e)
+//
+//	// Once per frame:
+//	m.Update()
+//	if m.Pressed("jump") {
+//		player.Jump()
+//	}
+//	player.X += m.Axis("move") * speed
+//
+// Bindings can be saved to, and loaded from, a section of a Config, and a
+// new binding can be captured from whatever the player presses next with
+// Capture().
+package input
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+type action struct {
+	bindings      []Binding
+	held, wasHeld bool
+}
+
+type axis struct {
+	bindings []AxisBinding
+	value    float32
+}
+
+type button struct {
+	down bool
+
+	// hit is set when the button goes down, and cleared by Update(), so
+	// that a press and release within the same frame isn't lost.
+	hit bool
+}
+
+type stickAxis struct {
+	stick, axis int
+}
+
+// Map holds a set of named actions and axes, together with the state of the
+// inputs they are bound to.
+type Map struct {
+	// DeadZone is how far a joystick axis has to be pushed before it
+	// registers, from 0 to 1.
+	DeadZone float32
+
+	actions map[string]*action
+	axes    map[string]*axis
+	buttons map[Input]*button
+	sticks  map[stickAxis]float32
+	capture func(Binding)
+}
+
+// NewMap() creates an empty input map.
+func NewMap() *Map {
+	return &Map{
+		DeadZone: 0.2,
+		actions:  make(map[string]*action),
+		axes:     make(map[string]*axis),
+		buttons:  make(map[Input]*button),
+		sticks:   make(map[stickAxis]float32),
+	}
+}
+
+// BindAction() sets the bindings of an action, replacing any it had before.
+func (m *Map) BindAction(name string, bindings ...Binding) {
+	a, ok := m.actions[name]
+	if !ok {
+		a = new(action)
+		m.actions[name] = a
+	}
+	a.bindings = append([]Binding(nil), bindings...)
+}
+
+// BindAxis() sets the bindings of an axis, replacing any it had before.
+func (m *Map) BindAxis(name string, bindings ...AxisBinding) {
+	a, ok := m.axes[name]
+	if !ok {
+		a = new(axis)
+		m.axes[name] = a
+	}
+	a.bindings = append([]AxisBinding(nil), bindings...)
+}
+
+// ActionBindings() returns the bindings of an action.
+func (m *Map) ActionBindings(name string) []Binding {
+	if a, ok := m.actions[name]; ok {
+		return append([]Binding(nil), a.bindings...)
+	}
+	return nil
+}
+
+// AxisBindings() returns the bindings of an axis.
+func (m *Map) AxisBindings(name string) []AxisBinding {
+	if a, ok := m.axes[name]; ok {
+		return append([]AxisBinding(nil), a.bindings...)
+	}
+	return nil
+}
+
+// Actions() returns the names of all actions, sorted.
+func (m *Map) Actions() []string {
+	names := make([]string, 0, len(m.actions))
+	for name := range m.actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Axes() returns the names of all axes, sorted.
+func (m *Map) Axes() []string {
+	names := make([]string, 0, len(m.axes))
+	for name := range m.axes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/* -- Feeding input -- */
+
+func (m *Map) setButton(in Input, down bool) {
+	b, ok := m.buttons[in]
+	if !ok {
+		b = new(button)
+		m.buttons[in] = b
+	}
+	if down && !b.down {
+		b.hit = true
+		m.captured(in)
+	}
+	b.down = down
+}
+
+func (m *Map) setStick(stick, ax int, pos float32) {
+	k := stickAxis{stick, ax}
+	old := m.sticks[k]
+	m.sticks[k] = pos
+	if m.capture == nil {
+		return
+	}
+	threshold := m.threshold()
+	if pos > threshold && old <= threshold {
+		m.captured(Input{Device: JoystickAxis, Stick: stick, Code: ax, Dir: 1})
+	} else if pos < -threshold && old >= -threshold {
+		m.captured(Input{Device: JoystickAxis, Stick: stick, Code: ax, Dir: -1})
+	}
+}
+
+// Handle() updates the state of the inputs from an event. Events that don't
+// come from a keyboard, mouse or joystick are ignored.
+func (m *Map) Handle(e interface{}) {
+	switch e := e.(type) {
+	case allegro.KeyDownEvent:
+		m.setButton(Input{Device: Key, Code: int(e.KeyCode())}, true)
+	case allegro.KeyUpEvent:
+		m.setButton(Input{Device: Key, Code: int(e.KeyCode())}, false)
+	case allegro.MouseButtonDownEvent:
+		m.setButton(Input{Device: MouseButton, Code: int(e.Button())}, true)
+	case allegro.MouseButtonUpEvent:
+		m.setButton(Input{Device: MouseButton, Code: int(e.Button())}, false)
+	case allegro.JoystickButtonDownEvent:
+		m.setButton(Input{Device: JoystickButton, Code: e.Button()}, true)
+	case allegro.JoystickButtonUpEvent:
+		m.setButton(Input{Device: JoystickButton, Code: e.Button()}, false)
+	case allegro.JoystickAxisEvent:
+		m.setStick(e.Stick(), e.Axis(), e.Pos())
+	}
+}
+
+// Poll() updates the state of the inputs from polled device states. Any of
+// them may be nil. Buttons held on any of the joysticks count as held, and
+// each axis takes the value of whichever joystick pushes it furthest.
+func (m *Map) Poll(keyboard *allegro.KeyboardState, mouse *allegro.MouseState, joysticks ...*allegro.JoystickState) {
+	if keyboard != nil {
+		for k := allegro.KeyCode(1); k < allegro.KEY_MAX; k++ {
+			m.setButton(Input{Device: Key, Code: int(k)}, keyboard.IsDown(k))
+		}
+	}
+	if mouse != nil {
+		buttons := mouse.Buttons()
+		for i := 0; i < 32; i++ {
+			m.setButton(Input{Device: MouseButton, Code: i + 1}, buttons&(1<<uint(i)) != 0)
+		}
+	}
+	if len(joysticks) == 0 {
+		return
+	}
+	held := make(map[int]bool)
+	sticks := make(map[stickAxis]float32)
+	for _, js := range joysticks {
+		if js == nil {
+			continue
+		}
+		for i, b := range js.Button {
+			held[i] = held[i] || b != 0
+		}
+		for s := range js.Stick {
+			for a, pos := range js.Stick[s].Axis {
+				k := stickAxis{s, a}
+				if abs(pos) > abs(sticks[k]) {
+					sticks[k] = pos
+				}
+			}
+		}
+	}
+	for i, down := range held {
+		m.setButton(Input{Device: JoystickButton, Code: i}, down)
+	}
+	for k, pos := range sticks {
+		m.setStick(k.stick, k.axis, pos)
+	}
+}
+
+// Reset() releases every input, e.g. when the display loses focus and key up
+// events can no longer be relied upon.
+func (m *Map) Reset() {
+	m.buttons = make(map[Input]*button)
+	m.sticks = make(map[stickAxis]float32)
+}
+
+/* -- Reading actions -- */
+
+func abs(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func (m *Map) threshold() float32 {
+	if m.DeadZone > 0.5 {
+		return m.DeadZone
+	}
+	return 0.5
+}
+
+var modifierKeys = []struct {
+	key allegro.KeyCode
+	mod allegro.KeyModifier
+}{
+	{allegro.KEY_LSHIFT, allegro.KEYMOD_SHIFT},
+	{allegro.KEY_RSHIFT, allegro.KEYMOD_SHIFT},
+	{allegro.KEY_LCTRL, allegro.KEYMOD_CTRL},
+	{allegro.KEY_RCTRL, allegro.KEYMOD_CTRL},
+	{allegro.KEY_ALT, allegro.KEYMOD_ALT},
+	{allegro.KEY_ALTGR, allegro.KEYMOD_ALTGR},
+	{allegro.KEY_LWIN, allegro.KEYMOD_LWIN},
+	{allegro.KEY_RWIN, allegro.KEYMOD_RWIN},
+	{allegro.KEY_MENU, allegro.KEYMOD_MENU},
+	{allegro.KEY_COMMAND, allegro.KEYMOD_COMMAND},
+}
+
+func isModifierKey(in Input) bool {
+	if in.Device != Key {
+		return false
+	}
+	for _, mk := range modifierKeys {
+		if in.Code == int(mk.key) {
+			return true
+		}
+	}
+	return false
+}
+
+// modifiers() works out the held modifiers from the state of the modifier
+// keys, since only KEY_CHAR events carry them.
+func (m *Map) modifiers() allegro.KeyModifier {
+	var mods allegro.KeyModifier
+	for _, mk := range modifierKeys {
+		if m.isHeld(Input{Device: Key, Code: int(mk.key)}) {
+			mods |= mk.mod
+		}
+	}
+	return mods
+}
+
+func (m *Map) isHeld(in Input) bool {
+	if in.Device == JoystickAxis {
+		return m.sticks[stickAxis{in.Stick, in.Code}]*float32(in.Dir) > m.threshold()
+	}
+	if b, ok := m.buttons[in]; ok {
+		return b.down || b.hit
+	}
+	return false
+}
+
+func (m *Map) bindingHeld(b Binding, mods allegro.KeyModifier) bool {
+	if len(b.Inputs) == 0 || mods&b.Modifiers != b.Modifiers {
+		return false
+	}
+	for _, in := range b.Inputs {
+		if !m.isHeld(in) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Map) analog(stick, ax int) float32 {
+	pos := m.sticks[stickAxis{stick, ax}]
+	if abs(pos) <= m.DeadZone || m.DeadZone >= 1 {
+		return 0
+	}
+	v := (abs(pos) - m.DeadZone) / (1 - m.DeadZone)
+	if pos < 0 {
+		return -v
+	}
+	return v
+}
+
+// Update() works out the state of every action and axis for the frame that
+// just ended. It should be called once per frame, after all of the frame's
+// input has been fed to the map.
+func (m *Map) Update() {
+	mods := m.modifiers()
+	for _, a := range m.actions {
+		a.wasHeld = a.held
+		a.held = false
+		for _, b := range a.bindings {
+			if m.bindingHeld(b, mods) {
+				a.held = true
+				break
+			}
+		}
+	}
+	for _, a := range m.axes {
+		var v float32
+		for _, b := range a.bindings {
+			if b.Analog {
+				v += m.analog(b.Stick, b.Axis)
+				continue
+			}
+			if m.bindingHeld(b.Negative, mods) {
+				v--
+			}
+			if m.bindingHeld(b.Positive, mods) {
+				v++
+			}
+		}
+		a.value = float32(math.Max(-1, math.Min(1, float64(v))))
+	}
+	for _, b := range m.buttons {
+		b.hit = false
+	}
+}
+
+// Held() returns true if the action was held during the last frame.
+func (m *Map) Held(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.held
+}
+
+// Pressed() returns true if the action started being held during the last
+// frame.
+func (m *Map) Pressed(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.held && !a.wasHeld
+}
+
+// Released() returns true if the action stopped being held during the last
+// frame.
+func (m *Map) Released(name string) bool {
+	a, ok := m.actions[name]
+	return ok && !a.held && a.wasHeld
+}
+
+// Axis() returns the value of the axis during the last frame, from -1 to 1.
+func (m *Map) Axis(name string) float32 {
+	if a, ok := m.axes[name]; ok {
+		return a.value
+	}
+	return 0
+}
+
+/* -- Rebinding -- */
+
+// Capture() waits for the next key, mouse button or joystick button to be
+// pressed, or joystick axis to be pushed, and passes it to f as a binding.
+// Modifier keys held at the time become the binding's modifiers; pressing a
+// modifier key on its own doesn't end the capture. Capturing doesn't stop
+// the input from affecting actions, so the caller will usually want to ignore
+// them until f has been called.
+func (m *Map) Capture(f func(Binding)) {
+	m.capture = f
+}
+
+// CancelCapture() stops waiting for input started by Capture().
+func (m *Map) CancelCapture() {
+	m.capture = nil
+}
+
+// Capturing() returns true while waiting for input started by Capture().
+func (m *Map) Capturing() bool {
+	return m.capture != nil
+}
+
+func (m *Map) captured(in Input) {
+	if m.capture == nil || isModifierKey(in) {
+		return
+	}
+	f := m.capture
+	m.capture = nil
+	f(Binding{Inputs: []Input{in}, Modifiers: m.modifiers()})
+}
+
+/* -- Saving and loading -- */
+
+// Save() writes the bindings of every action and axis to a section of cfg,
+// one key per name.
+func (m *Map) Save(cfg *allegro.Config, section string) {
+	for _, name := range m.Actions() {
+		var parts []string
+		for _, b := range m.actions[name].bindings {
+			parts = append(parts, b.String())
+		}
+		cfg.SetValue(section, name, strings.Join(parts, ", "))
+	}
+	for _, name := range m.Axes() {
+		var parts []string
+		for _, b := range m.axes[name].bindings {
+			parts = append(parts, b.String())
+		}
+		cfg.SetValue(section, name, strings.Join(parts, ", "))
+	}
+}
+
+// Load() replaces the bindings of every action and axis that has a key in a
+// section of cfg. Actions and axes have to be created with BindAction() or
+// BindAxis() first, so that the map knows which is which; other keys in the
+// section are ignored. Nothing is changed if any of the bindings can't be
+// parsed.
+func (m *Map) Load(cfg *allegro.Config, section string) error {
+	actions := make(map[string][]Binding)
+	axes := make(map[string][]AxisBinding)
+	for name := range m.actions {
+		value, err := cfg.Value(section, name)
+		if err != nil {
+			continue
+		}
+		bindings := []Binding{}
+		for _, s := range splitList(value) {
+			b, err := ParseBinding(s)
+			if err != nil {
+				return err
+			}
+			bindings = append(bindings, b)
+		}
+		actions[name] = bindings
+	}
+	for name := range m.axes {
+		value, err := cfg.Value(section, name)
+		if err != nil {
+			continue
+		}
+		bindings := []AxisBinding{}
+		for _, s := range splitList(value) {
+			b, err := ParseAxisBinding(s)
+			if err != nil {
+				return err
+			}
+			bindings = append(bindings, b)
+		}
+		axes[name] = bindings
+	}
+	for name, bindings := range actions {
+		m.BindAction(name, bindings...)
+	}
+	for name, bindings := range axes {
+		m.BindAxis(name, bindings...)
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
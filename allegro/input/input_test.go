@@ -0,0 +1,174 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+var event allegro.Event
+
+func keyDown(k allegro.KeyCode) interface{} { return event.SetKeyDown(0, k, nil) }
+func keyUp(k allegro.KeyCode) interface{}   { return event.SetKeyUp(0, k, nil) }
+
+func frame(m *Map, events ...interface{}) {
+	for _, e := range events {
+		m.Handle(e)
+	}
+	m.Update()
+}
+
+func checkEdges(t *testing.T, m *Map, name string, pressed, held, released bool) {
+	t.Helper()
+	if m.Pressed(name) != pressed || m.Held(name) != held || m.Released(name) != released {
+		t.Errorf("%s: pressed/held/released = %v/%v/%v, want %v/%v/%v", name,
+			m.Pressed(name), m.Held(name), m.Released(name), pressed, held, released)
+	}
+}
+
+func TestEdges(t *testing.T) {
+	m := NewMap()
+	m.BindAction("jump", KeyBinding(allegro.KEY_SPACE), JoystickButtonBinding(0))
+
+	frame(m, keyDown(allegro.KEY_SPACE))
+	checkEdges(t, m, "jump", true, true, false)
+	frame(m)
+	checkEdges(t, m, "jump", false, true, false)
+	frame(m, keyUp(allegro.KEY_SPACE))
+	checkEdges(t, m, "jump", false, false, true)
+	frame(m)
+	checkEdges(t, m, "jump", false, false, false)
+
+	// A tap that starts and ends within one frame still counts.
+	frame(m, event.SetJoystickButtonDown(0, nil, 0), event.SetJoystickButtonUp(0, nil, 0))
+	checkEdges(t, m, "jump", true, true, false)
+	frame(m)
+	checkEdges(t, m, "jump", false, false, true)
+}
+
+func TestChordAndModifiers(t *testing.T) {
+	m := NewMap()
+	m.BindAction("save", KeyBinding(allegro.KEY_S).With(allegro.KEYMOD_CTRL))
+	m.BindAction("combo", Chord(KeyBinding(allegro.KEY_A), MouseButtonBinding(1)))
+
+	frame(m, keyDown(allegro.KEY_S))
+	checkEdges(t, m, "save", false, false, false)
+	frame(m, keyDown(allegro.KEY_LCTRL))
+	checkEdges(t, m, "save", true, true, false)
+
+	frame(m, keyDown(allegro.KEY_A))
+	checkEdges(t, m, "combo", false, false, false)
+	frame(m, event.SetMouseButtonDown(0, 0, 0, 0, 0, 1, nil))
+	checkEdges(t, m, "combo", true, true, false)
+}
+
+func TestAxes(t *testing.T) {
+	m := NewMap()
+	m.DeadZone = 0.2
+	m.BindAxis("move", DigitalAxis(KeyBinding(allegro.KEY_LEFT), KeyBinding(allegro.KEY_RIGHT)), AnalogAxis(0, 0))
+	m.BindAction("up", JoystickAxisBinding(0, 1, -1))
+
+	frame(m, keyDown(allegro.KEY_LEFT))
+	if v := m.Axis("move"); v != -1 {
+		t.Errorf("move with left held = %v, want -1", v)
+	}
+	frame(m, keyUp(allegro.KEY_LEFT), event.SetJoystickAxis(0, nil, 0, 0, 0.1))
+	if v := m.Axis("move"); v != 0 {
+		t.Errorf("move inside dead zone = %v, want 0", v)
+	}
+	frame(m, event.SetJoystickAxis(0, nil, 0, 0, 0.6))
+	if v := m.Axis("move"); v < 0.4999 || v > 0.5001 {
+		t.Errorf("move at 0.6 = %v, want 0.5", v)
+	}
+	frame(m, keyDown(allegro.KEY_RIGHT))
+	if v := m.Axis("move"); v != 1 {
+		t.Errorf("move is %v, want it clamped to 1", v)
+	}
+
+	frame(m, event.SetJoystickAxis(0, nil, 0, 1, -0.9))
+	checkEdges(t, m, "up", true, true, false)
+}
+
+func TestCapture(t *testing.T) {
+	m := NewMap()
+	var got *Binding
+	m.Capture(func(b Binding) { got = &b })
+	frame(m, keyDown(allegro.KEY_LSHIFT))
+	if got != nil {
+		t.Fatal("capture ended on a modifier key")
+	}
+	frame(m, keyDown(allegro.KEY_F))
+	want := KeyBinding(allegro.KEY_F).With(allegro.KEYMOD_SHIFT)
+	if got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("captured %v, want %v", got, want)
+	}
+	if m.Capturing() {
+		t.Error("still capturing after a binding was captured")
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, b := range []Binding{
+		KeyBinding(allegro.KEY_ESCAPE),
+		KeyBinding(allegro.KEY_S).With(allegro.KEYMOD_CTRL | allegro.KEYMOD_SHIFT),
+		MouseButtonBinding(2),
+		JoystickButtonBinding(7),
+		JoystickAxisBinding(1, 2, -1),
+		Chord(KeyBinding(allegro.KEY_A), KeyBinding(allegro.KEY_B)),
+	} {
+		got, err := ParseBinding(b.String())
+		if err != nil {
+			t.Errorf("%s: %v", b, err)
+		} else if !reflect.DeepEqual(got, b) {
+			t.Errorf("%s parsed back as %v", b, got)
+		}
+	}
+	for k, want := range map[allegro.KeyCode]string{
+		allegro.KEY_PAD_0:  "key:PAD_0",
+		allegro.KEY_ESCAPE: "key:ESCAPE",
+		allegro.KEY_1:      "key:DIGIT_1",
+		allegro.KEY_0:      "key:DIGIT_0",
+	} {
+		// Key names come from a fixed table, so they work without the
+		// keyboard driver.
+		if got := KeyBinding(k).String(); got != want {
+			t.Errorf("key %d written as '%s', want '%s'", k, got, want)
+		}
+		if got, err := ParseBinding(want); err != nil || !reflect.DeepEqual(got, KeyBinding(k)) {
+			t.Errorf("'%s' parsed back as %v (%v)", want, got, err)
+		}
+	}
+	// Bindings written by key code still load.
+	if got, err := ParseBinding("key:28"); err != nil || !reflect.DeepEqual(got, KeyBinding(allegro.KEY_1)) {
+		t.Errorf("'key:28' parsed as %v (%v)", got, err)
+	}
+	for _, s := range []string{"", "key:", "joy:axis:0:1:x", "mouse:left", "ctrl"} {
+		if _, err := ParseBinding(s); err == nil {
+			t.Errorf("'%s' parsed without error", s)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	m := NewMap()
+	m.BindAction("fire", KeyBinding(allegro.KEY_Z), MouseButtonBinding(1))
+	m.BindAxis("turn", DigitalAxis(KeyBinding(allegro.KEY_A), KeyBinding(allegro.KEY_D)), AnalogAxis(0, 0))
+
+	cfg := allegro.CreateConfig()
+	defer cfg.Destroy()
+	m.Save(cfg, "controls")
+
+	loaded := NewMap()
+	loaded.BindAction("fire")
+	loaded.BindAxis("turn")
+	if err := loaded.Load(cfg, "controls"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := loaded.ActionBindings("fire"), m.ActionBindings("fire"); !reflect.DeepEqual(got, want) {
+		t.Errorf("fire: %v, want %v", got, want)
+	}
+	if got, want := loaded.AxisBindings("turn"), m.AxisBindings("turn"); !reflect.DeepEqual(got, want) {
+		t.Errorf("turn: %v, want %v", got, want)
+	}
+}
@@ -3,7 +3,6 @@ package allegro
 // #include <allegro5/allegro.h>
 import "C"
 import (
-	"errors"
 	"fmt"
 )
 
@@ -32,7 +31,7 @@ type stickState struct {
 func InstallJoystick() error {
 	success := bool(C.al_install_joystick())
 	if !success {
-		return errors.New("failed to install joystick!")
+		return &Error{Op: "install joystick"}
 	}
 	return nil
 }
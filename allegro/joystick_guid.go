@@ -0,0 +1,36 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+// #include <stdint.h>
+// #include <string.h>
+/*
+// al_get_joystick_guid() only exists from Allegro 5.2.11 on.
+static int joystick_guid(ALLEGRO_JOYSTICK *joy, uint8_t *out) {
+#if ALLEGRO_VERSION_INT >= AL_ID(5, 2, 11, 0)
+	ALLEGRO_JOYSTICK_GUID guid = al_get_joystick_guid(joy);
+	memcpy(out, guid.val, 16);
+	return 1;
+#else
+	(void)joy;
+	(void)out;
+	return 0;
+#endif
+}
+*/
+import "C"
+import (
+	"encoding/hex"
+	"unsafe"
+)
+
+// Returns the joystick's GUID, in the hexadecimal form used by SDL's game
+// controller database. The second return value is false if the GUID isn't
+// available, because Allegro is too old to provide it.
+func (j *Joystick) GUID() (string, bool) {
+	var guid [16]byte
+	if C.joystick_guid((*C.ALLEGRO_JOYSTICK)(j), (*C.uint8_t)(unsafe.Pointer(&guid[0]))) == 0 {
+		return "", false
+	}
+	return hex.EncodeToString(guid[:]), true
+}
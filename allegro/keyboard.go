@@ -2,9 +2,6 @@ package allegro
 
 // #include <allegro5/allegro.h>
 import "C"
-import (
-	"errors"
-)
 
 type Keyboard C.ALLEGRO_KEYBOARD
 
@@ -132,6 +129,7 @@ const (
 	KEY_BACKQUOTE            = C.ALLEGRO_KEY_BACKQUOTE
 	KEY_SEMICOLON2           = C.ALLEGRO_KEY_SEMICOLON2
 	KEY_COMMAND              = C.ALLEGRO_KEY_COMMAND
+	KEY_MAX                  = C.ALLEGRO_KEY_MAX
 )
 
 //}}}
@@ -165,7 +163,7 @@ const (
 func InstallKeyboard() error {
 	success := bool(C.al_install_keyboard())
 	if !success {
-		return errors.New("failed to install keyboard")
+		return &Error{Op: "install keyboard"}
 	}
 	return nil
 }
@@ -214,7 +212,7 @@ func SetKeyboardLeds(leds int) bool {
 func KeyboardEventSource() (*EventSource, error) {
 	source := C.al_get_keyboard_event_source()
 	if source == nil {
-		return nil, errors.New("failed to get keyboard event source; did you call InstallKeyboard() first?")
+		return nil, &Error{Op: "get keyboard event source", Err: ErrNotInstalled}
 	}
 	return (*EventSource)(source), nil
 }
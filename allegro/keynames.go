@@ -0,0 +1,166 @@
+package allegro
+
+import (
+	"strings"
+)
+
+// keyNames holds the names of the key constants, without the KEY_ prefix.
+// Unlike the names that Allegro gives keys, they are the same for every
+// keyboard driver, and are known before the keyboard is installed. The digit
+// keys are called DIGIT_0 to DIGIT_9, since plain numbers are taken to be key
+// codes.
+var keyNames = map[KeyCode]string{
+	KEY_A:            "A",
+	KEY_B:            "B",
+	KEY_C:            "C",
+	KEY_D:            "D",
+	KEY_E:            "E",
+	KEY_F:            "F",
+	KEY_G:            "G",
+	KEY_H:            "H",
+	KEY_I:            "I",
+	KEY_J:            "J",
+	KEY_K:            "K",
+	KEY_L:            "L",
+	KEY_M:            "M",
+	KEY_N:            "N",
+	KEY_O:            "O",
+	KEY_P:            "P",
+	KEY_Q:            "Q",
+	KEY_R:            "R",
+	KEY_S:            "S",
+	KEY_T:            "T",
+	KEY_U:            "U",
+	KEY_V:            "V",
+	KEY_W:            "W",
+	KEY_X:            "X",
+	KEY_Y:            "Y",
+	KEY_Z:            "Z",
+	KEY_0:            "DIGIT_0",
+	KEY_1:            "DIGIT_1",
+	KEY_2:            "DIGIT_2",
+	KEY_3:            "DIGIT_3",
+	KEY_4:            "DIGIT_4",
+	KEY_5:            "DIGIT_5",
+	KEY_6:            "DIGIT_6",
+	KEY_7:            "DIGIT_7",
+	KEY_8:            "DIGIT_8",
+	KEY_9:            "DIGIT_9",
+	KEY_PAD_0:        "PAD_0",
+	KEY_PAD_1:        "PAD_1",
+	KEY_PAD_2:        "PAD_2",
+	KEY_PAD_3:        "PAD_3",
+	KEY_PAD_4:        "PAD_4",
+	KEY_PAD_5:        "PAD_5",
+	KEY_PAD_6:        "PAD_6",
+	KEY_PAD_7:        "PAD_7",
+	KEY_PAD_8:        "PAD_8",
+	KEY_PAD_9:        "PAD_9",
+	KEY_F1:           "F1",
+	KEY_F2:           "F2",
+	KEY_F3:           "F3",
+	KEY_F4:           "F4",
+	KEY_F5:           "F5",
+	KEY_F6:           "F6",
+	KEY_F7:           "F7",
+	KEY_F8:           "F8",
+	KEY_F9:           "F9",
+	KEY_F10:          "F10",
+	KEY_F11:          "F11",
+	KEY_F12:          "F12",
+	KEY_ESCAPE:       "ESCAPE",
+	KEY_TILDE:        "TILDE",
+	KEY_MINUS:        "MINUS",
+	KEY_EQUALS:       "EQUALS",
+	KEY_BACKSPACE:    "BACKSPACE",
+	KEY_TAB:          "TAB",
+	KEY_OPENBRACE:    "OPENBRACE",
+	KEY_CLOSEBRACE:   "CLOSEBRACE",
+	KEY_ENTER:        "ENTER",
+	KEY_SEMICOLON:    "SEMICOLON",
+	KEY_QUOTE:        "QUOTE",
+	KEY_BACKSLASH:    "BACKSLASH",
+	KEY_BACKSLASH2:   "BACKSLASH2",
+	KEY_COMMA:        "COMMA",
+	KEY_FULLSTOP:     "FULLSTOP",
+	KEY_SLASH:        "SLASH",
+	KEY_SPACE:        "SPACE",
+	KEY_INSERT:       "INSERT",
+	KEY_DELETE:       "DELETE",
+	KEY_HOME:         "HOME",
+	KEY_END:          "END",
+	KEY_PGUP:         "PGUP",
+	KEY_PGDN:         "PGDN",
+	KEY_LEFT:         "LEFT",
+	KEY_RIGHT:        "RIGHT",
+	KEY_UP:           "UP",
+	KEY_DOWN:         "DOWN",
+	KEY_PAD_SLASH:    "PAD_SLASH",
+	KEY_PAD_ASTERISK: "PAD_ASTERISK",
+	KEY_PAD_MINUS:    "PAD_MINUS",
+	KEY_PAD_PLUS:     "PAD_PLUS",
+	KEY_PAD_DELETE:   "PAD_DELETE",
+	KEY_PAD_ENTER:    "PAD_ENTER",
+	KEY_PRINTSCREEN:  "PRINTSCREEN",
+	KEY_PAUSE:        "PAUSE",
+	KEY_ABNT_C1:      "ABNT_C1",
+	KEY_YEN:          "YEN",
+	KEY_KANA:         "KANA",
+	KEY_CONVERT:      "CONVERT",
+	KEY_NOCONVERT:    "NOCONVERT",
+	KEY_AT:           "AT",
+	KEY_CIRCUMFLEX:   "CIRCUMFLEX",
+	KEY_COLON2:       "COLON2",
+	KEY_KANJI:        "KANJI",
+	KEY_LSHIFT:       "LSHIFT",
+	KEY_RSHIFT:       "RSHIFT",
+	KEY_LCTRL:        "LCTRL",
+	KEY_RCTRL:        "RCTRL",
+	KEY_ALT:          "ALT",
+	KEY_ALTGR:        "ALTGR",
+	KEY_LWIN:         "LWIN",
+	KEY_RWIN:         "RWIN",
+	KEY_MENU:         "MENU",
+	KEY_SCROLLLOCK:   "SCROLLLOCK",
+	KEY_NUMLOCK:      "NUMLOCK",
+	KEY_CAPSLOCK:     "CAPSLOCK",
+	KEY_PAD_EQUALS:   "PAD_EQUALS",
+	KEY_BACKQUOTE:    "BACKQUOTE",
+	KEY_SEMICOLON2:   "SEMICOLON2",
+	KEY_COMMAND:      "COMMAND",
+}
+
+var keyCodes = func() map[string]KeyCode {
+	m := make(map[string]KeyCode, len(keyNames))
+	for k, name := range keyNames {
+		m[name] = k
+	}
+	return m
+}()
+
+// StableName() returns the name of the key's constant without the KEY_
+// prefix, such as "ESCAPE" for KEY_ESCAPE or "DIGIT_1" for KEY_1, or "" if
+// there is none. Unlike
+// Name(), it doesn't depend on the keyboard driver, nor need the keyboard to
+// be installed, which makes it the one to write to files.
+func (k KeyCode) StableName() string {
+	return keyNames[k]
+}
+
+// KeyCodeByName() looks up a key by its StableName(), ignoring case. While the
+// keyboard is installed, the names given by its driver are accepted too, for
+// files written with those.
+func KeyCodeByName(name string) (KeyCode, bool) {
+	if k, ok := keyCodes[strings.ToUpper(name)]; ok {
+		return k, true
+	}
+	if name == "" || !IsKeyboardInstalled() {
+		return 0, false
+	}
+	for k := KeyCode(1); k < KEY_MAX; k++ {
+		if strings.EqualFold(k.Name(), name) {
+			return k, true
+		}
+	}
+	return 0, false
+}
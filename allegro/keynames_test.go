@@ -0,0 +1,26 @@
+package allegro
+
+import (
+	"testing"
+)
+
+func TestKeyNamesRoundTrip(t *testing.T) {
+	if IsKeyboardInstalled() {
+		t.Skip("the keyboard is installed")
+	}
+	for k, name := range keyNames {
+		str := formatKeyCode(k)
+		if str != name {
+			t.Errorf("key %d is written as '%s', want '%s'", k, str, name)
+		}
+		if got, err := parseKeyCode(str); err != nil || got != k {
+			t.Errorf("'%s' is read back as %d (%v), want %d", str, got, err, k)
+		}
+	}
+	if k, err := parseKeyCode("escape"); err != nil || k != KEY_ESCAPE {
+		t.Errorf("'escape' is read as %d (%v), want KEY_ESCAPE", k, err)
+	}
+	if _, err := parseKeyCode("NO_SUCH_KEY"); err == nil {
+		t.Error("unknown key name accepted")
+	}
+}
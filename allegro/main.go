@@ -10,9 +10,11 @@ func go_main() {
 	if err := install(); err != nil {
 		panic(err)
 	}
+	startDispatcher()
 	if _main != nil {
 		_main()
 	}
+	stopDispatcher()
 	uninstall()
 }
 
@@ -7,7 +7,6 @@ package memfile
 import "C"
 import (
 	"bytes"
-	"errors"
 	"unsafe"
 
 	"github.com/phrasz/nag/allegro"
@@ -39,7 +38,7 @@ func Open(mem unsafe.Pointer, size int64, mode FileMode) (*allegro.File, error)
 	defer C.free_string(mode_)
 	f := C.al_open_memfile(mem, C.int64_t(size), mode_)
 	if f == nil {
-		return nil, errors.New("failed to open memfile")
+		return nil, &allegro.Error{Op: "open memfile", Addon: "memfile"}
 	}
 	return (*allegro.File)(unsafe.Pointer(f)), nil
 }
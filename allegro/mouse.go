@@ -6,6 +6,8 @@ import (
 	"errors"
 )
 
+type Mouse C.ALLEGRO_MOUSE
+
 type MouseCursor C.ALLEGRO_MOUSE_CURSOR
 
 type MouseState C.struct_ALLEGRO_MOUSE_STATE
@@ -38,7 +40,7 @@ const (
 func InstallMouse() error {
 	success := bool(C.al_install_mouse())
 	if !success {
-		return errors.New("failed to install mouse!")
+		return &Error{Op: "install mouse"}
 	}
 	return nil
 }
@@ -130,7 +132,7 @@ func SetMouseAxis(which, value int) error {
 func MouseEventSource() (*EventSource, error) {
 	source := C.al_get_mouse_event_source()
 	if source == nil {
-		return nil, errors.New("failed to get mouse event source; did you call InstallMouse() first?")
+		return nil, &Error{Op: "get mouse event source", Err: ErrNotInstalled}
 	}
 	return (*EventSource)(source), nil
 }
@@ -139,7 +141,7 @@ func MouseEventSource() (*EventSource, error) {
 func CreateMouseCursor(bmp *Bitmap, x_focus, y_focus int) (*MouseCursor, error) {
 	c := C.al_create_mouse_cursor((*C.ALLEGRO_BITMAP)(bmp), C.int(x_focus), C.int(y_focus))
 	if c == nil {
-		return nil, errors.New("failed to create mouse cursor!")
+		return nil, &Error{Op: "create mouse cursor"}
 	}
 	cursor := (*MouseCursor)(c)
 	//runtime.SetFinalizer(cursor, cursor.Destroy)
@@ -154,6 +156,7 @@ func (cursor *MouseCursor) Destroy() {
 // Set the given mouse cursor to be the current mouse cursor for the given
 // display.
 func (d *Display) SetMouseCursor(cursor *MouseCursor) error {
+	checkDisplayThread("Display.SetMouseCursor", d)
 	success := C.al_set_mouse_cursor((*C.ALLEGRO_DISPLAY)(d), (*C.ALLEGRO_MOUSE_CURSOR)(cursor))
 	if !success {
 		return errors.New("failed to set display mouse cursor!")
@@ -165,6 +168,7 @@ func (d *Display) SetMouseCursor(cursor *MouseCursor) error {
 // given display. If the cursor is currently 'shown' (as opposed to 'hidden')
 // the change is immediately visible.
 func (d *Display) SetSystemMouseCursor(cursor SystemMouseCursor) error {
+	checkDisplayThread("Display.SetSystemMouseCursor", d)
 	success := C.al_set_system_mouse_cursor((*C.ALLEGRO_DISPLAY)(d), (C.ALLEGRO_SYSTEM_MOUSE_CURSOR)(cursor))
 	if !success {
 		return errors.New("failed to set display system mouse cursor!")
@@ -188,6 +192,7 @@ func MouseCursorPosition() (int, int, error) {
 // Hide the mouse cursor in the given display. This has no effect on what the
 // current mouse cursor looks like; it just makes it disappear.
 func (d *Display) HideMouseCursor() error {
+	checkDisplayThread("Display.HideMouseCursor", d)
 	success := bool(C.al_hide_mouse_cursor((*C.ALLEGRO_DISPLAY)(d)))
 	if !success {
 		return errors.New("failed to hide mouse cursor!")
@@ -197,6 +202,7 @@ func (d *Display) HideMouseCursor() error {
 
 // Make a mouse cursor visible in the given display.
 func (d *Display) ShowMouseCursor() error {
+	checkDisplayThread("Display.ShowMouseCursor", d)
 	success := bool(C.al_show_mouse_cursor((*C.ALLEGRO_DISPLAY)(d)))
 	if !success {
 		return errors.New("failed to show mouse cursor!")
@@ -207,6 +213,7 @@ func (d *Display) ShowMouseCursor() error {
 // Confine the mouse cursor to the given display. The mouse cursor can only be
 // confined to one display at a time.
 func (d *Display) GrabMouse() error {
+	checkDisplayThread("Display.GrabMouse", d)
 	success := bool(C.al_grab_mouse((*C.ALLEGRO_DISPLAY)(d)))
 	if !success {
 		return errors.New("failed to grab mouse!")
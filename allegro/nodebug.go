@@ -0,0 +1,11 @@
+//go:build !allegrodebug
+// +build !allegrodebug
+
+package allegro
+
+// These are no-ops unless built with the allegrodebug tag; see debug.go.
+
+func trackDisplay(d *Display)                  {}
+func untrackDisplay(d *Display)                {}
+func checkDisplayThread(op string, d *Display) {}
+func checkCurrentDisplay(op string)            {}
@@ -0,0 +1,4 @@
+package opengl
+
+// #cgo !windows pkg-config: allegro-5
+import "C"
@@ -0,0 +1,164 @@
+// Package opengl provides access to the OpenGL objects behind Allegro's
+// displays and bitmaps, so that custom OpenGL rendering can share textures
+// with Allegro. It only applies to displays created with the allegro.OPENGL
+// flag, or on platforms where OpenGL is the default.
+//
+// Allegro can't adopt a texture created elsewhere, so textures are shared the
+// other way around: create a bitmap with CreateTextureBitmap(), and render
+// into its texture, or its FBO, with OpenGL:
+//
+//	bmp, tex, err := opengl.CreateTextureBitmap(256, 256)
+//	...
+//	fbo, _ := opengl.FBO(bmp)
+//	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(fbo))
+//	// draw with OpenGL
+//	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+//	bmp.Draw(x, y, 0)
+package opengl
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_opengl.h>
+// #include "../util.c"
+import "C"
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// Texture is the name of an OpenGL texture.
+type Texture uint32
+
+// Framebuffer is the name of an OpenGL framebuffer object.
+type Framebuffer uint32
+
+type Variant int
+
+const (
+	DESKTOP_OPENGL Variant = C.ALLEGRO_DESKTOP_OPENGL
+	OPENGL_ES              = C.ALLEGRO_OPENGL_ES
+)
+
+func (v Variant) String() string {
+	switch v {
+	case DESKTOP_OPENGL:
+		return "OpenGL"
+	case OPENGL_ES:
+		return "OpenGL ES"
+	}
+	return "unknown"
+}
+
+func bitmap(bmp *allegro.Bitmap) *C.ALLEGRO_BITMAP {
+	return (*C.ALLEGRO_BITMAP)(unsafe.Pointer(bmp))
+}
+
+// Returns the OpenGL or OpenGL ES version number of the client (the computer
+// the program is running on), for the current display, packed in the same
+// format as al_get_allegro_version.
+func Version() (major, minor, revision, release uint8) {
+	v := uint32(C.al_get_opengl_version())
+	major = uint8(v >> 24)
+	minor = uint8((v >> 16) & 255)
+	revision = uint8((v >> 8) & 255)
+	release = uint8(v & 255)
+	return
+}
+
+// Returns the variant or type of OpenGL used on the running platform.
+func GetVariant() Variant {
+	return Variant(C.al_get_opengl_variant())
+}
+
+// This function is a helper to determine whether an OpenGL extension is
+// available on the given display or not.
+func HaveExtension(extension string) bool {
+	extension_ := C.CString(extension)
+	defer C.free_string(extension_)
+	return bool(C.al_have_opengl_extension(extension_))
+}
+
+// Helper to get the address of an OpenGL symbol, for loading functions that
+// aren't part of the core profile. Returns nil if the symbol isn't found.
+func ProcAddress(name string) unsafe.Pointer {
+	name_ := C.CString(name)
+	defer C.free_string(name_)
+	return unsafe.Pointer(C.al_get_opengl_proc_address(name_))
+}
+
+// Make the OpenGL context associated with the given display current for the
+// calling thread. Calls made by a custom renderer go to the current context,
+// so this is needed when more than one display is open.
+func SetCurrentContext(d *allegro.Display) {
+	C.al_set_current_opengl_context((*C.ALLEGRO_DISPLAY)(unsafe.Pointer(d)))
+}
+
+// Returns the OpenGL texture id internally used by the given bitmap if it uses
+// one, else an error.
+func GetTexture(bmp *allegro.Bitmap) (Texture, error) {
+	tex := C.al_get_opengl_texture(bitmap(bmp))
+	if tex == 0 {
+		return 0, &allegro.Error{Op: "get opengl texture"}
+	}
+	return Texture(tex), nil
+}
+
+// Retrieves the size of the texture used for the bitmap. This can be
+// different from the bitmap size if OpenGL only supports power-of-two sizes
+// or if it is a sub-bitmap.
+func TextureSize(bmp *allegro.Bitmap) (w, h int, err error) {
+	var cw, ch C.int
+	if !bool(C.al_get_opengl_texture_size(bitmap(bmp), &cw, &ch)) {
+		return 0, 0, &allegro.Error{Op: "get opengl texture size"}
+	}
+	return int(cw), int(ch), nil
+}
+
+// Returns the u/v coordinates for the top/left corner of the bitmap within
+// the used texture, in pixels.
+func TexturePosition(bmp *allegro.Bitmap) (u, v int) {
+	var cu, cv C.int
+	C.al_get_opengl_texture_position(bitmap(bmp), &cu, &cv)
+	return int(cu), int(cv)
+}
+
+// Returns the OpenGL FBO id internally used by the given bitmap if it uses
+// one, otherwise returns an error. No attempt will be made to create an FBO
+// if the bitmap is not owned by the current display.
+func FBO(bmp *allegro.Bitmap) (Framebuffer, error) {
+	fbo := C.al_get_opengl_fbo(bitmap(bmp))
+	if fbo == 0 {
+		return 0, &allegro.Error{Op: "get opengl fbo"}
+	}
+	return Framebuffer(fbo), nil
+}
+
+// Explicitly free an OpenGL FBO created for a bitmap, if it has one. Usually
+// you do not need to worry about freeing FBOs, unless you use GetTexture or
+// FBO directly on bitmaps that are used as target.
+func RemoveFBO(bmp *allegro.Bitmap) {
+	C.al_remove_opengl_fbo(bitmap(bmp))
+}
+
+// CreateTextureBitmap() creates a video bitmap on the current display, and
+// returns it along with its texture, for sharing with OpenGL code. The
+// texture may be larger than the bitmap; see TextureSize() and
+// TexturePosition(). The thread's new bitmap flags are used, except that the
+// bitmap is always a video bitmap, and since Allegro doesn't back up textures
+// changed behind its back, it is created with NO_PRESERVE_TEXTURE.
+func CreateTextureBitmap(w, h int) (*allegro.Bitmap, Texture, error) {
+	flags := allegro.NewBitmapFlags()
+	defer allegro.SetNewBitmapFlags(flags)
+	allegro.SetNewBitmapFlags(flags&^allegro.MEMORY_BITMAP | allegro.VIDEO_BITMAP | allegro.NO_PRESERVE_TEXTURE)
+	bmp := allegro.CreateBitmap(w, h)
+	if bmp == nil {
+		return nil, 0, &allegro.Error{Op: fmt.Sprintf("create %dx%d texture bitmap", w, h)}
+	}
+	tex, err := GetTexture(bmp)
+	if err != nil {
+		bmp.Destroy()
+		return nil, 0, err
+	}
+	return bmp, tex, nil
+}
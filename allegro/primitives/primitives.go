@@ -10,7 +10,6 @@ int get_stride() {
 */
 import "C"
 import (
-	"errors"
 	"unsafe"
 
 	"github.com/phrasz/nag/allegro"
@@ -20,10 +19,7 @@ func col(color allegro.Color) C.ALLEGRO_COLOR {
 	return *((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color)))
 }
 
-type Point struct {
-	X float32
-	Y float32
-}
+type Point = allegro.Point
 
 type Vertex struct {
 	X, Y, Z float32
@@ -101,7 +97,7 @@ const (
 func Install() error {
 	ok := bool(C.al_init_primitives_addon())
 	if !ok {
-		return errors.New("failed to initialize primitives addon")
+		return &allegro.Error{Op: "install", Addon: "primitives"}
 	}
 	return nil
 }
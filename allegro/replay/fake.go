@@ -0,0 +1,81 @@
+package replay
+
+import (
+	"io"
+	"sync"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// FakeQueue is a Queue that returns a fixed list of records as events,
+// without needing a display, input drivers or even a running Allegro system.
+// It's meant for testing input handling code. The events' timestamps are the
+// records' times.
+type FakeQueue struct {
+	Sources
+
+	lock    sync.Mutex
+	records []Record
+}
+
+// NewFakeQueue() creates a fake queue holding the given records.
+func NewFakeQueue(records ...Record) *FakeQueue {
+	return &FakeQueue{records: records}
+}
+
+// Push() adds records to the end of the queue.
+func (q *FakeQueue) Push(records ...Record) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.records = append(q.records, records...)
+}
+
+// Load() adds every record in a recording to the end of the queue.
+func (q *FakeQueue) Load(r io.Reader) error {
+	records, err := ReadAll(r)
+	q.Push(records...)
+	return err
+}
+
+func (q *FakeQueue) pop(event *allegro.Event) (interface{}, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.records) == 0 {
+		return nil, false
+	}
+	rec := q.records[0]
+	q.records = q.records[1:]
+	if event == nil {
+		event = new(allegro.Event)
+	}
+	return rec.Event(event, &q.Sources, rec.Time), true
+}
+
+func (q *FakeQueue) IsEmpty() bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.records) == 0
+}
+
+// GetNextEvent() returns the next record as an event, or EmptyQueue if there
+// are none left.
+func (q *FakeQueue) GetNextEvent(event *allegro.Event) (interface{}, error) {
+	if e, ok := q.pop(event); ok {
+		return e, nil
+	}
+	return nil, allegro.EmptyQueue
+}
+
+// WaitForEvent() returns the next record as an event. Since nothing else can
+// add to a fake queue while the caller is blocked, it returns nil instead of
+// waiting when the queue is empty.
+func (q *FakeQueue) WaitForEvent(event *allegro.Event) interface{} {
+	e, _ := q.pop(event)
+	return e
+}
+
+// WaitForEventTimed() returns the next record as an event, or false right
+// away if the queue is empty.
+func (q *FakeQueue) WaitForEventTimed(event *allegro.Event, secs float32) (interface{}, bool) {
+	return q.pop(event)
+}
@@ -0,0 +1,180 @@
+package replay
+
+import (
+	"sync"
+	"time"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// Player feeds a recording back into a real event queue. Recorded events are
+// emitted through a user event source registered with the queue, and come out
+// of it as what they were when they were recorded, so the queue is read with
+// its own WaitForEvent(), Events() or Dispatch() as usual. Live input sources
+// should usually not be registered with a queue being replayed into.
+//
+// Recorded user events that carried a Go value come out as UserEvents from
+// the player's source, whose Value() is a UserValue. Like any other value
+// event, they need to be unreferenced.
+//
+// Recorded events can be played back at their original pace with Play(), or
+// one frame at a time with Step(). Either way the replayed events' timestamps
+// keep the same spacing they had when they were recorded. Emitting an event
+// fails if the player's source has been unregistered from every queue; the
+// record that failed is kept, and replayed again by the next Play() or
+// Step().
+type Player struct {
+	Sources
+
+	queue  *allegro.EventQueue
+	source *allegro.UserEventSource
+	base   float64
+
+	lock    sync.Mutex
+	records []Record
+	next    int
+	stop    chan struct{}
+	err     error
+}
+
+// NewPlayer() creates a player that replays records into queue. Nothing is
+// replayed until Play() or Step() is called.
+func NewPlayer(queue *allegro.EventQueue, records []Record) *Player {
+	p := &Player{
+		queue:   queue,
+		source:  allegro.NewUserEventSource(),
+		base:    allegro.Time(),
+		records: records,
+	}
+	queue.Register(p.source)
+	return p
+}
+
+// deliver() emits the next n records into the queue, stopping at the first
+// one that fails.
+func (p *Player) deliver(n int) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if rest := len(p.records) - p.next; n > rest {
+		n = rest
+	}
+	if n == 0 {
+		return false, nil
+	}
+	for _, rec := range p.records[p.next : p.next+n] {
+		if err := p.emit(rec); err != nil {
+			return false, err
+		}
+		p.next++
+	}
+	return true, nil
+}
+
+func (p *Player) emit(rec Record) error {
+	var event allegro.Event
+	switch e := rec.Event(&event, &p.Sources, p.base+rec.Time).(type) {
+	case nil:
+		return nil
+	case UserValue:
+		return p.source.Emit(e)
+	default:
+		return p.source.EmitEvent(&event)
+	}
+}
+
+// Play() starts replaying the remaining records in the background, at the
+// pace they were recorded. If a record can't be emitted, playback stops, and
+// Err() returns the reason.
+func (p *Player) Play() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.stop != nil || p.next >= len(p.records) {
+		return
+	}
+	p.err = nil
+	p.stop = make(chan struct{})
+	go p.run(p.stop, p.records[p.next].Time)
+}
+
+func (p *Player) run(stop chan struct{}, offset float64) {
+	begin := time.Now()
+	for {
+		p.lock.Lock()
+		if p.next >= len(p.records) {
+			if p.stop == stop {
+				p.stop = nil
+			}
+			p.lock.Unlock()
+			return
+		}
+		at := p.records[p.next].Time - offset
+		p.lock.Unlock()
+
+		t := time.NewTimer(time.Duration(at*float64(time.Second)) - time.Since(begin))
+		select {
+		case <-stop:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if _, err := p.deliver(1); err != nil {
+			p.lock.Lock()
+			if p.stop == stop {
+				p.stop = nil
+				p.err = err
+			}
+			p.lock.Unlock()
+			return
+		}
+	}
+}
+
+// Err() returns the error that stopped the last Play(), or nil if it is still
+// playing, or was paused or finished without one.
+func (p *Player) Err() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.err
+}
+
+// Pause() stops replaying records in the background. Play() picks up where
+// it left off.
+func (p *Player) Pause() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+// Step() replays the records up to and including the next timer event, which
+// for a game driven by a timer amounts to a single frame's worth of input. It
+// returns false if there was nothing left to replay, or if a record couldn't
+// be emitted, along with the error.
+func (p *Player) Step() (bool, error) {
+	p.lock.Lock()
+	n := 0
+	for i := p.next; i < len(p.records); i++ {
+		n++
+		if p.records[i].Kind == Timer {
+			break
+		}
+	}
+	p.lock.Unlock()
+	return p.deliver(n)
+}
+
+// Done() returns true once every record has been replayed.
+func (p *Player) Done() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.next >= len(p.records)
+}
+
+// Destroy() stops the player and unregisters it from its queue.
+func (p *Player) Destroy() {
+	p.Pause()
+	p.queue.UnregisterEventSource(p.source.EventSource())
+	p.source.Destroy()
+}
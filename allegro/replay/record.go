@@ -0,0 +1,266 @@
+// Package replay records the input read from an event queue, and plays it
+// back later, either at the original pace or one frame at a time.
+//
+// Recordings are written as a stream of JSON objects, one per line. The
+// first line is a header naming the format and its version; every line after
+// that is a Record. Only keyboard, mouse, joystick, timer and user events are
+// recorded. Pointers such as the display or timer an event came from can't
+// be recorded, so they are filled back in from a Sources on playback.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+const (
+	// Format is the name written to the header of every recording.
+	Format = "nag-replay"
+
+	// Version is the version of the recording format written by this
+	// package. Recordings with a newer version are rejected.
+	Version = 1
+)
+
+// Kind identifies the type of a recorded event.
+type Kind string
+
+const (
+	JoystickAxis       Kind = "joystick_axis"
+	JoystickButtonDown Kind = "joystick_button_down"
+	JoystickButtonUp   Kind = "joystick_button_up"
+	KeyDown            Kind = "key_down"
+	KeyUp              Kind = "key_up"
+	KeyChar            Kind = "key_char"
+	MouseAxes          Kind = "mouse_axes"
+	MouseButtonDown    Kind = "mouse_button_down"
+	MouseButtonUp      Kind = "mouse_button_up"
+	MouseWarped        Kind = "mouse_warped"
+	Timer              Kind = "timer"
+	User               Kind = "user"
+)
+
+// Record is a single recorded event. Time is relative to the first event in
+// the recording, in seconds. Only the fields that make sense for the Kind
+// are set.
+type Record struct {
+	Kind Kind    `json:"kind"`
+	Time float64 `json:"time"`
+
+	KeyCode   allegro.KeyCode     `json:"keycode,omitempty"`
+	Unichar   int                 `json:"unichar,omitempty"`
+	Modifiers allegro.KeyModifier `json:"modifiers,omitempty"`
+	Repeat    bool                `json:"repeat,omitempty"`
+
+	X      int `json:"x,omitempty"`
+	Y      int `json:"y,omitempty"`
+	Z      int `json:"z,omitempty"`
+	W      int `json:"w,omitempty"`
+	Dx     int `json:"dx,omitempty"`
+	Dy     int `json:"dy,omitempty"`
+	Dz     int `json:"dz,omitempty"`
+	Dw     int `json:"dw,omitempty"`
+	Button int `json:"button,omitempty"`
+
+	// Joystick is the index of the joystick, as passed to GetJoystick(), or
+	// -1 if it couldn't be found.
+	Joystick int     `json:"joystick,omitempty"`
+	Stick    int     `json:"stick,omitempty"`
+	Axis     int     `json:"axis,omitempty"`
+	Pos      float32 `json:"pos,omitempty"`
+
+	Count int64 `json:"count,omitempty"`
+
+	// Data holds a user event's four data fields. If the event carried a Go
+	// value (see UserEventSource.Emit()) it is stored as JSON in Value
+	// instead, and comes back as a UserValue on playback.
+	Data  []uintptr       `json:"data,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// UserValue is what a recorded user event that carried a Go value turns into
+// on playback, since the original value can't be recreated.
+type UserValue struct {
+	Timestamp float64
+	Value     json.RawMessage
+}
+
+// Sources holds the pointers that recorded events can't carry. They are
+// filled in to the events returned on playback.
+type Sources struct {
+	Display   *allegro.Display
+	Timer     *allegro.Timer
+	Source    *allegro.EventSource
+	Joysticks []*allegro.Joystick
+}
+
+func (s *Sources) joystick(i int) *allegro.Joystick {
+	if i < 0 || i >= len(s.Joysticks) {
+		return nil
+	}
+	return s.Joysticks[i]
+}
+
+func joystickIndex(j *allegro.Joystick) int {
+	if j == nil || !allegro.IsJoystickInstalled() {
+		return -1
+	}
+	for i := 0; i < allegro.NumJoysticks(); i++ {
+		if js, err := allegro.GetJoystick(i); err == nil && js == j {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewRecord() converts an event into a Record, with its time taken relative
+// to start. It returns false for events that aren't recorded.
+func NewRecord(e interface{}, start float64) (Record, bool) {
+	var r Record
+	switch e := e.(type) {
+	case allegro.JoystickAxisEvent:
+		r = Record{Kind: JoystickAxis, Time: e.Timestamp(), Joystick: joystickIndex(e.Id()), Stick: e.Stick(), Axis: e.Axis(), Pos: e.Pos()}
+	case allegro.JoystickButtonDownEvent:
+		r = Record{Kind: JoystickButtonDown, Time: e.Timestamp(), Joystick: joystickIndex(e.Id()), Button: e.Button()}
+	case allegro.JoystickButtonUpEvent:
+		r = Record{Kind: JoystickButtonUp, Time: e.Timestamp(), Joystick: joystickIndex(e.Id()), Button: e.Button()}
+	case allegro.KeyDownEvent:
+		r = Record{Kind: KeyDown, Time: e.Timestamp(), KeyCode: e.KeyCode()}
+	case allegro.KeyUpEvent:
+		r = Record{Kind: KeyUp, Time: e.Timestamp(), KeyCode: e.KeyCode()}
+	case allegro.KeyCharEvent:
+		r = Record{Kind: KeyChar, Time: e.Timestamp(), KeyCode: e.KeyCode(), Unichar: e.Unichar(), Modifiers: e.Modifiers(), Repeat: e.Repeat()}
+	case allegro.MouseAxesEvent:
+		r = Record{Kind: MouseAxes, Time: e.Timestamp(), X: e.X(), Y: e.Y(), Z: e.Z(), W: e.W(), Dx: e.Dx(), Dy: e.Dy(), Dz: e.Dz(), Dw: e.Dw()}
+	case allegro.MouseWarpedEvent:
+		r = Record{Kind: MouseWarped, Time: e.Timestamp(), X: e.X(), Y: e.Y(), Z: e.Z(), W: e.W(), Dx: e.Dx(), Dy: e.Dy(), Dz: e.Dz(), Dw: e.Dw()}
+	case allegro.MouseButtonDownEvent:
+		r = Record{Kind: MouseButtonDown, Time: e.Timestamp(), X: e.X(), Y: e.Y(), Z: e.Z(), W: e.W(), Button: int(e.Button())}
+	case allegro.MouseButtonUpEvent:
+		r = Record{Kind: MouseButtonUp, Time: e.Timestamp(), X: e.X(), Y: e.Y(), Z: e.Z(), W: e.W(), Button: int(e.Button())}
+	case allegro.TimerEvent:
+		r = Record{Kind: Timer, Time: e.Timestamp(), Count: e.Count()}
+	case allegro.UserEvent:
+		r = Record{Kind: User, Time: e.Timestamp()}
+		if v := e.Value(); v != nil {
+			if data, err := json.Marshal(v); err == nil {
+				r.Value = data
+			}
+		} else {
+			r.Data = []uintptr{e.Data1(), e.Data2(), e.Data3(), e.Data4()}
+		}
+	default:
+		return r, false
+	}
+	r.Time -= start
+	return r, true
+}
+
+// Event() turns the record back into an event, stored in buf, with the given
+// timestamp. The pointers that couldn't be recorded are taken from sources.
+func (r *Record) Event(buf *allegro.Event, sources *Sources, timestamp float64) interface{} {
+	switch r.Kind {
+	case JoystickAxis:
+		return buf.SetJoystickAxis(timestamp, sources.joystick(r.Joystick), r.Stick, r.Axis, r.Pos)
+	case JoystickButtonDown:
+		return buf.SetJoystickButtonDown(timestamp, sources.joystick(r.Joystick), r.Button)
+	case JoystickButtonUp:
+		return buf.SetJoystickButtonUp(timestamp, sources.joystick(r.Joystick), r.Button)
+	case KeyDown:
+		return buf.SetKeyDown(timestamp, r.KeyCode, sources.Display)
+	case KeyUp:
+		return buf.SetKeyUp(timestamp, r.KeyCode, sources.Display)
+	case KeyChar:
+		return buf.SetKeyChar(timestamp, r.KeyCode, r.Unichar, r.Modifiers, r.Repeat, sources.Display)
+	case MouseAxes:
+		return buf.SetMouseAxes(timestamp, r.X, r.Y, r.Z, r.W, r.Dx, r.Dy, r.Dz, r.Dw, sources.Display)
+	case MouseWarped:
+		return buf.SetMouseWarped(timestamp, r.X, r.Y, r.Z, r.W, r.Dx, r.Dy, r.Dz, r.Dw, sources.Display)
+	case MouseButtonDown:
+		return buf.SetMouseButtonDown(timestamp, r.X, r.Y, r.Z, r.W, uint(r.Button), sources.Display)
+	case MouseButtonUp:
+		return buf.SetMouseButtonUp(timestamp, r.X, r.Y, r.Z, r.W, uint(r.Button), sources.Display)
+	case Timer:
+		return buf.SetTimer(timestamp, sources.Timer, r.Count)
+	case User:
+		if r.Value != nil {
+			return UserValue{Timestamp: timestamp, Value: r.Value}
+		}
+		return buf.SetUser(timestamp, sources.Source, r.Data...)
+	}
+	return nil
+}
+
+type header struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+// Writer writes records to a recording.
+type Writer struct {
+	enc *json.Encoder
+}
+
+// NewWriter() starts a new recording by writing its header to w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header{Format, Version}); err != nil {
+		return nil, err
+	}
+	return &Writer{enc: enc}, nil
+}
+
+// Write() appends a record to the recording.
+func (w *Writer) Write(r Record) error {
+	return w.enc.Encode(&r)
+}
+
+// Reader reads records back from a recording.
+type Reader struct {
+	dec *json.Decoder
+}
+
+// NewReader() reads and checks the header of a recording.
+func NewReader(r io.Reader) (*Reader, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var h header
+	if err := dec.Decode(&h); err != nil {
+		return nil, fmt.Errorf("failed to read replay header: %v", err)
+	}
+	if h.Format != Format {
+		return nil, fmt.Errorf("not a replay: format is '%s'", h.Format)
+	}
+	if h.Version > Version {
+		return nil, fmt.Errorf("unsupported replay version %d (newest supported is %d)", h.Version, Version)
+	}
+	return &Reader{dec: dec}, nil
+}
+
+// Read() returns the next record, or io.EOF at the end of the recording.
+func (r *Reader) Read() (Record, error) {
+	var rec Record
+	err := r.dec.Decode(&rec)
+	return rec, err
+}
+
+// ReadAll() reads an entire recording.
+func ReadAll(r io.Reader) ([]Record, error) {
+	rd, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for {
+		rec, err := rd.Read()
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
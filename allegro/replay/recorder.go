@@ -0,0 +1,91 @@
+package replay
+
+import (
+	"io"
+	"sync"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// Queue is the part of *allegro.EventQueue that a game's main loop reads
+// events through. *allegro.EventQueue, Recorder and FakeQueue all implement
+// it, so a main loop written against Queue can be recorded, replayed (see
+// Player) or tested without changes.
+type Queue interface {
+	IsEmpty() bool
+	GetNextEvent(event *allegro.Event) (interface{}, error)
+	WaitForEvent(event *allegro.Event) interface{}
+	WaitForEventTimed(event *allegro.Event, secs float32) (interface{}, bool)
+}
+
+// Recorder wraps a Queue, writing every recordable event read through it to
+// a recording.
+type Recorder struct {
+	Queue
+
+	lock    sync.Mutex
+	w       *Writer
+	start   float64
+	started bool
+	err     error
+}
+
+// NewRecorder() creates a recorder reading from queue and writing to w.
+func NewRecorder(queue Queue, w io.Writer) (*Recorder, error) {
+	wr, err := NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Queue: queue, w: wr}, nil
+}
+
+// Err() returns the first error hit while writing the recording, if any.
+// Recording stops after an error.
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+func (r *Recorder) record(e interface{}) {
+	if e == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return
+	}
+	if !r.started {
+		rec, ok := NewRecord(e, 0)
+		if !ok {
+			return
+		}
+		r.start, r.started = rec.Time, true
+	}
+	if rec, ok := NewRecord(e, r.start); ok {
+		r.err = r.w.Write(rec)
+	}
+}
+
+func (r *Recorder) GetNextEvent(event *allegro.Event) (interface{}, error) {
+	e, err := r.Queue.GetNextEvent(event)
+	if err == nil {
+		r.record(e)
+	}
+	return e, err
+}
+
+func (r *Recorder) WaitForEvent(event *allegro.Event) interface{} {
+	e := r.Queue.WaitForEvent(event)
+	r.record(e)
+	return e
+}
+
+func (r *Recorder) WaitForEventTimed(event *allegro.Event, secs float32) (interface{}, bool) {
+	e, ok := r.Queue.WaitForEventTimed(event, secs)
+	if ok {
+		r.record(e)
+	}
+	return e, ok
+}
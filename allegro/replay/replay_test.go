@@ -0,0 +1,170 @@
+package replay
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+var script = []Record{
+	{Kind: KeyDown, Time: 0, KeyCode: allegro.KEY_LEFT},
+	{Kind: KeyChar, Time: 0, KeyCode: allegro.KEY_A, Unichar: 'a', Modifiers: allegro.KEYMOD_SHIFT, Repeat: true},
+	{Kind: MouseAxes, Time: 0.25, X: 10, Y: 20, Dx: 1, Dy: -2},
+	{Kind: MouseButtonDown, Time: 0.5, X: 10, Y: 20, Button: 1},
+	{Kind: Timer, Time: 0.5, Count: 30},
+	{Kind: KeyUp, Time: 1, KeyCode: allegro.KEY_LEFT},
+	{Kind: User, Time: 1.5, Data: []uintptr{1, 2, 3, 4}},
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(NewFakeQueue(script...), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event allegro.Event
+	for !rec.IsEmpty() {
+		if _, err := rec.GetNextEvent(&event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rec.GetNextEvent(&event); err != allegro.EmptyQueue {
+		t.Errorf("empty queue returned %v, want EmptyQueue", err)
+	}
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(script) {
+		t.Fatalf("got %d records, want %d", len(got), len(script))
+	}
+	for i := range got {
+		if !reflect.DeepEqual(got[i], script[i]) {
+			t.Errorf("record %d: %+v, want %+v", i, got[i], script[i])
+		}
+	}
+}
+
+func TestFakeQueueEvents(t *testing.T) {
+	q := NewFakeQueue(script[:3]...)
+	var event allegro.Event
+	if e, ok := q.WaitForEvent(&event).(allegro.KeyDownEvent); !ok || e.KeyCode() != allegro.KEY_LEFT {
+		t.Errorf("first event: %#v, want key down for KEY_LEFT", e)
+	}
+	if e, ok := q.WaitForEvent(&event).(allegro.KeyCharEvent); !ok || e.Unichar() != 'a' || !e.Repeat() {
+		t.Errorf("second event: %#v, want repeated key char 'a'", e)
+	}
+	if e, ok := q.WaitForEvent(&event).(allegro.MouseAxesEvent); !ok || e.X() != 10 || e.Dy() != -2 || e.Timestamp() != 0.25 {
+		t.Errorf("third event: %#v, want mouse axes at (10, 20)", e)
+	}
+	if _, ok := q.WaitForEventTimed(&event, 1); ok {
+		t.Error("empty fake queue returned an event")
+	}
+}
+
+func TestReaderRejectsNewerVersion(t *testing.T) {
+	_, err := NewReader(strings.NewReader(`{"format":"nag-replay","version":99}` + "\n"))
+	if err == nil {
+		t.Error("newer replay version accepted")
+	}
+	_, err = NewReader(strings.NewReader(`{"format":"something-else","version":1}` + "\n"))
+	if err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestPlayerStep(t *testing.T) {
+	if err := allegro.Init(allegro.NoAtExit()); err != nil {
+		t.Fatal(err)
+	}
+	defer allegro.Shutdown()
+	queue, err := allegro.CreateEventQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Destroy()
+	p := NewPlayer(queue, script)
+	defer p.Destroy()
+
+	// The first frame ends with the timer event.
+	if ok, err := p.Step(); !ok {
+		t.Fatalf("nothing was replayed (%v)", err)
+	}
+	var event allegro.Event
+	var got []interface{}
+	for !queue.IsEmpty() {
+		e, err := queue.GetNextEvent(&event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+		if k, ok := e.(allegro.KeyDownEvent); ok && k.KeyCode() != allegro.KEY_LEFT {
+			t.Errorf("replayed key down for %v, want KEY_LEFT", k.KeyCode())
+		}
+		if m, ok := e.(allegro.MouseAxesEvent); ok && (m.X() != 10 || m.Dy() != -2) {
+			t.Errorf("replayed mouse axes at (%d, %d)", m.X(), m.Y())
+		}
+	}
+	if len(got) != 5 {
+		t.Fatalf("first frame replayed %d events, want 5", len(got))
+	}
+	if _, ok := got[4].(allegro.TimerEvent); !ok {
+		t.Errorf("first frame ended with %#v, want a timer event", got[4])
+	}
+
+	for {
+		ok, err := p.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+	}
+	if !p.Done() {
+		t.Error("player isn't done after stepping through the recording")
+	}
+}
+
+func TestPlayerEmitError(t *testing.T) {
+	if err := allegro.Init(allegro.NoAtExit()); err != nil {
+		t.Fatal(err)
+	}
+	defer allegro.Shutdown()
+	queue, err := allegro.CreateEventQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Destroy()
+	p := NewPlayer(queue, script)
+	defer p.Destroy()
+	// With its source registered with no queue, the player can't emit.
+	queue.UnregisterEventSource(p.source.EventSource())
+
+	if ok, err := p.Step(); ok || err == nil {
+		t.Errorf("Step() = %v, %v without a queue to emit into", ok, err)
+	}
+	p.Play()
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if p.Err() == nil {
+		t.Error("Play() didn't report the failure to emit")
+	}
+	if p.Done() {
+		t.Error("the record that failed counts as replayed")
+	}
+	queue.Register(p.source)
+	if ok, err := p.Step(); !ok || err != nil {
+		t.Errorf("Step() = %v, %v once the source is registered again", ok, err)
+	}
+}
@@ -0,0 +1,286 @@
+package allegro
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Destroyer is implemented by everything that has to be freed by hand:
+// bitmaps, fonts, samples, displays and so on.
+type Destroyer interface {
+	Destroy()
+}
+
+// Lifetime counts the shutdowns of an addon. Allegro frees everything an
+// addon created when the addon is shut down, so resources created before then
+// must not be destroyed again afterwards. Addon packages call End() after
+// shutting down, and pass their Lifetime to RegisterResource().
+type Lifetime struct {
+	mu    sync.Mutex
+	ended int
+}
+
+// End() marks the end of the addon's current lifetime.
+func (l *Lifetime) End() {
+	l.mu.Lock()
+	l.ended++
+	l.mu.Unlock()
+}
+
+func (l *Lifetime) current() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ended
+}
+
+type resourceType struct {
+	order    int
+	lifetime *Lifetime
+}
+
+var resourceTypes sync.Map
+
+func init() {
+	// Bitmaps belong to a display, so they have to go first.
+	RegisterResource((*Display)(nil), 10, nil)
+}
+
+// RegisterResource() tells Scope how to handle resources of the same type as
+// example. Resources are destroyed in ascending order, and the ones with the
+// same order in the reverse of the order they were added in. The default is
+// 0; resources that use others, like fonts using their glyph bitmaps or
+// sample instances playing a sample, need a lower one. If lifetime isn't nil,
+// resources created before it ends aren't destroyed after it. Addon packages
+// register their types in init().
+func RegisterResource(example Destroyer, order int, lifetime *Lifetime) {
+	resourceTypes.Store(reflect.TypeOf(example), resourceType{order, lifetime})
+}
+
+func resourceTypeOf(r Destroyer) resourceType {
+	if t, ok := resourceTypes.Load(reflect.TypeOf(r)); ok {
+		return t.(resourceType)
+	}
+	return resourceType{}
+}
+
+// Handle is a resource tracked by a Scope.
+type Handle struct {
+	Resource Destroyer
+
+	session  int
+	typ      resourceType
+	lifetime int
+	stack    []uintptr
+}
+
+// Stack() returns the stack trace of the call to Scope.Add() that started
+// tracking the resource.
+func (h *Handle) Stack() string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(h.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// Scope keeps track of resources so that they can all be destroyed at once,
+// in an order that respects the dependencies between them; see
+// RegisterResource(). It is opt-in: resources are only tracked once they are
+// added.
+//
+//	scope := allegro.NewScope()
+//	defer scope.Close()
+//	bmp, err := allegro.LoadBitmap("sprites.png")
+//	if err != nil {
+//		return err
+//	}
+//	scope.Add(bmp)
+//
+// Resources are always destroyed on the main thread, and only while Allegro is
+// still running; everything left over when Run() returns has already been
+// freed by Allegro itself. If a scope becomes unreachable without having been
+// closed, its resources are destroyed on the main thread at some point after
+// the garbage collector notices, except for those added while Allegro wasn't
+// running under Run() or Init(), which are never destroyed by the garbage
+// collector.
+type Scope struct {
+	mu      sync.Mutex
+	handles []*Handle
+	closed  bool
+}
+
+// NewScope() creates an empty scope.
+func NewScope() *Scope {
+	s := &Scope{}
+	runtime.SetFinalizer(s, (*Scope).finalize)
+	return s
+}
+
+// Add() starts tracking r, which is destroyed when the scope is closed.
+// Adding to a closed scope panics.
+func (s *Scope) Add(r Destroyer) {
+	typ := resourceTypeOf(r)
+	h := &Handle{
+		Resource: r,
+		session:  currentSession(),
+		typ:      typ,
+		lifetime: typ.lifetime.current(),
+		stack:    callers(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		panic("allegro: Scope.Add() called on a closed scope")
+	}
+	s.handles = append(s.handles, h)
+}
+
+func callers() []uintptr {
+	pc := make([]uintptr, 32)
+	// Skip runtime.Callers(), callers() and Scope.Add().
+	return pc[:runtime.Callers(3, pc)]
+}
+
+// take() removes the handle of r from the scope, and returns it.
+func (s *Scope) take(r Destroyer) *Handle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, h := range s.handles {
+		if h.Resource == r {
+			s.handles = append(s.handles[:i], s.handles[i+1:]...)
+			return h
+		}
+	}
+	return nil
+}
+
+// Destroy() destroys r right away, and stops tracking it. It does nothing if
+// r isn't tracked by the scope.
+func (s *Scope) Destroy(r Destroyer) {
+	if h := s.take(r); h != nil {
+		Do(func() { destroyHandles([]*Handle{h}) })
+	}
+}
+
+// Release() stops tracking r without destroying it, passing ownership back
+// to the caller.
+func (s *Scope) Release(r Destroyer) {
+	s.take(r)
+}
+
+// Live() returns the resources that the scope is still tracking, in the order
+// they were added.
+func (s *Scope) Live() []*Handle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Handle(nil), s.handles...)
+}
+
+// LeakReport() lists the resources that the scope is still tracking, along
+// with where they were added, or returns "" if there are none. It is meant for
+// tests that check everything they create gets destroyed:
+//
+//	defer func() {
+//		if report := scope.LeakReport(); report != "" {
+//			t.Error(report)
+//		}
+//		scope.Close()
+//	}()
+func (s *Scope) LeakReport() string {
+	live := s.Live()
+	if len(live) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "live resources: %d\n", len(live))
+	for _, h := range live {
+		fmt.Fprintf(&b, "\n%T %p, added at:\n%s", h.Resource, h.Resource, h.Stack())
+	}
+	return b.String()
+}
+
+// Close() destroys all the resources that the scope is tracking, and waits
+// for that to finish. It always returns nil; it returns an error so that a
+// scope is an io.Closer.
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	handles := s.handles
+	s.handles = nil
+	s.closed = true
+	s.mu.Unlock()
+	runtime.SetFinalizer(s, nil)
+	Do(func() { destroyHandles(handles) })
+	return nil
+}
+
+// finalize() is like Close(), but doesn't wait, since the finalizer goroutine
+// can't block on the main thread.
+func (s *Scope) finalize() {
+	finalizeHandles(s.handles)
+}
+
+// finalizeHandles() queues up handles to be destroyed on the main thread,
+// for a finalizer. Allegro can't be called from the finalizer goroutine, so
+// nothing is destroyed if Allegro isn't running, and resources that were
+// added while it wasn't running are always left alone.
+func finalizeHandles(handles []*Handle) {
+	var live []*Handle
+	for _, h := range handles {
+		if h.session != 0 {
+			live = append(live, h)
+		}
+	}
+	if len(live) > 0 {
+		post(func() { destroyHandles(live) })
+	}
+}
+
+// destroyHandles() is run on the main thread. Resources that were added while
+// Allegro wasn't running under Run() aren't tied to a session, so only their
+// addon's lifetime is checked.
+func destroyHandles(handles []*Handle) {
+	sorted := make([]*Handle, len(handles))
+	for i, h := range handles {
+		sorted[len(handles)-1-i] = h
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].typ.order < sorted[j].typ.order
+	})
+	session := currentSession()
+	for _, h := range sorted {
+		if (h.session == 0 || h.session == session) && h.lifetime == h.typ.lifetime.current() {
+			h.Resource.Destroy()
+		}
+	}
+}
+
+// Finalize() makes the garbage collector destroy obj once it becomes
+// unreachable, by queueing up a call to its Destroy() method on the main
+// thread; Allegro's functions can't be called from the finalizer goroutine.
+// Nothing happens if Allegro wasn't running under Run() or Init() when
+// Finalize() was called, or if it, or the addon obj belongs to, has been shut
+// down by then. obj must be a pointer to memory allocated by Go, such as an
+// *audio.Stream or a struct wrapping a resource, and not one of the types that
+// point straight at Allegro's memory, like *Bitmap. Its Destroy() method
+// should clear the finalizer with runtime.SetFinalizer(obj, nil), and do
+// nothing when called twice.
+func Finalize(obj Destroyer) {
+	h := &Handle{session: currentSession(), typ: resourceTypeOf(obj)}
+	h.lifetime = h.typ.lifetime.current()
+	runtime.SetFinalizer(obj, func(obj Destroyer) {
+		h.Resource = obj
+		finalizeHandles([]*Handle{h})
+	})
+}
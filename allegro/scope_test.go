@@ -0,0 +1,98 @@
+package allegro
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeResource struct {
+	name string
+	log  *[]string
+}
+
+func (r *fakeResource) Destroy() {
+	*r.log = append(*r.log, r.name)
+}
+
+type fakeDependent struct{ fakeResource }
+
+type fakeOwner struct{ fakeResource }
+
+func TestScopeOrder(t *testing.T) {
+	var lifetime Lifetime
+	RegisterResource((*fakeDependent)(nil), -1, nil)
+	RegisterResource((*fakeOwner)(nil), 1, &lifetime)
+
+	var log []string
+	s := NewScope()
+	s.Add(&fakeOwner{fakeResource{"owner", &log}})
+	s.Add(&fakeResource{"a", &log})
+	s.Add(&fakeDependent{fakeResource{"dependent", &log}})
+	s.Add(&fakeResource{"b", &log})
+	s.Close()
+	if want := []string{"dependent", "b", "a", "owner"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("destroyed %q, want %q", log, want)
+	}
+
+	// Nothing is destroyed after its addon has shut down.
+	log = nil
+	s = NewScope()
+	s.Add(&fakeOwner{fakeResource{"owner", &log}})
+	lifetime.End()
+	s.Add(&fakeOwner{fakeResource{"new owner", &log}})
+	s.Close()
+	if want := []string{"new owner"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("destroyed %q after shutdown, want %q", log, want)
+	}
+}
+
+func TestScopeDestroyAndRelease(t *testing.T) {
+	var log []string
+	s := NewScope()
+	a, b, c := &fakeResource{"a", &log}, &fakeResource{"b", &log}, &fakeResource{"c", &log}
+	s.Add(a)
+	s.Add(b)
+	s.Add(c)
+	s.Destroy(b)
+	s.Release(c)
+	if got := s.Live(); len(got) != 1 || got[0].Resource != a {
+		t.Errorf("live resources are %v", got)
+	}
+	s.Close()
+	if want := []string{"b", "a"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("destroyed %q, want %q", log, want)
+	}
+}
+
+func TestScopeLeakReport(t *testing.T) {
+	var log []string
+	s := NewScope()
+	defer s.Close()
+	if report := s.LeakReport(); report != "" {
+		t.Errorf("empty scope reports %q", report)
+	}
+	s.Add(&fakeResource{"a", &log})
+	report := s.LeakReport()
+	if !strings.HasPrefix(report, "live resources: 1\n") ||
+		!strings.Contains(report, "*allegro.fakeResource") ||
+		!strings.Contains(report, "TestScopeLeakReport") {
+		t.Errorf("leak report is:\n%s", report)
+	}
+}
+
+func TestScopeFinalizeWithoutAllegro(t *testing.T) {
+	if IsSystemInstalled() {
+		t.Skip("allegro is installed")
+	}
+	var log []string
+	s := NewScope()
+	s.Add(&fakeResource{"a", &log})
+	// Nothing may be destroyed on the finalizer goroutine, so without Allegro
+	// running the resource is left alone.
+	s.finalize()
+	if len(log) != 0 {
+		t.Errorf("finalizer destroyed %v", log)
+	}
+	s.Close()
+}
@@ -2,9 +2,6 @@ package allegro
 
 // #include <allegro5/allegro.h>
 import "C"
-import (
-	"fmt"
-)
 
 type State C.ALLEGRO_STATE
 
@@ -22,14 +19,6 @@ const (
 	STATE_ALL                               = C.ALLEGRO_STATE_ALL
 )
 
-type Error struct {
-	Errno int
-}
-
-func (e *Error) Error() string {
-	return fmt.Sprintf("errno = %d", e.Errno)
-}
-
 // Stores part of the state of the current thread in the given ALLEGRO_STATE
 // objects. The flags parameter can take any bit-combination of these flags:
 func StoreState(flags StateFlags) *State {
@@ -43,15 +32,3 @@ func StoreState(flags StateFlags) *State {
 func RestoreState(state *State) {
 	C.al_restore_state((*C.ALLEGRO_STATE)(state))
 }
-
-// Some Allegro functions will set an error number as well as returning an
-// error code. Call this function to retrieve the last error number set for the
-// calling thread.
-func LastError() error {
-	return &Error{int(C.al_get_errno())}
-}
-
-// Set the error number for for the calling thread.
-func SetLastError(e *Error) {
-	C.al_set_errno(C.int(e.Errno))
-}
@@ -72,18 +72,49 @@ A bare-bones program might look something like this:
     		}
     	})
     }
+
+Threads
+
+Allegro ties a display, and the graphics context behind it, to the thread
+that created it. Functions that draw to or manage a display must be called
+from that thread, which is normally the one running the function passed to
+Run(). This includes CreateDisplay, FlipDisplay, UpdateDisplayRegion,
+SetTargetBackbuffer, the Display methods that change the window (Destroy,
+Resize, AcknowledgeResize, SetDisplayFlag and the mouse cursor methods),
+and any drawing or locking of video bitmaps. The target bitmap, the new
+bitmap and display parameters and the current transformation are also kept
+per thread; see StoreState() and RunThreadWithState().
+
+Goroutines are free to run on any thread, so code outside of the main loop
+should use Do(), DoAsync() or DoErr() to hand thread-affine work over to the
+main thread:
+
+    go func() {
+    	img := decode(path) // plain Go work can happen anywhere
+    	allegro.Do(func() {
+    		bmp, _ = allegro.ImageToBitmap(img)
+    	})
+    }()
+
+Building with -tags allegrodebug makes the display functions and methods
+listed above panic when they're called from the wrong thread. CreateDisplay
+and bitmap drawing aren't checked, since Allegro can't tell which thread
+they were meant for.
 */
 package allegro
 
 // #include <allegro5/allegro.h>
+// #include <stdlib.h>
 /*
-bool _al_init() {
-	return al_init();
+static bool install_system(bool use_atexit) {
+	return al_install_system(ALLEGRO_VERSION_INT, use_atexit ? atexit : NULL);
 }
 */
 import "C"
 import (
 	"errors"
+	"fmt"
+	"runtime"
 )
 
 // Returns the (compiled) version of the Allegro library, packed into a single
@@ -142,9 +173,208 @@ func AppName() string {
 	return C.GoString(C.al_get_app_name())
 }
 
-func install() error {
-	if !bool(C._al_init()) {
-		return errors.New("failed to initialize allegro!")
+type StandardPath int
+
+const (
+	RESOURCES_PATH      StandardPath = C.ALLEGRO_RESOURCES_PATH
+	TEMP_PATH                        = C.ALLEGRO_TEMP_PATH
+	USER_HOME_PATH                   = C.ALLEGRO_USER_HOME_PATH
+	USER_DOCUMENTS_PATH              = C.ALLEGRO_USER_DOCUMENTS_PATH
+	USER_DATA_PATH                   = C.ALLEGRO_USER_DATA_PATH
+	USER_SETTINGS_PATH               = C.ALLEGRO_USER_SETTINGS_PATH
+	EXENAME_PATH                     = C.ALLEGRO_EXENAME_PATH
+)
+
+// Gets a system path, depending on the id parameter. Some of these paths may
+// be affected by the organization and application name, so be sure to set
+// those before calling this function. The paths are not guaranteed to be
+// unique (e.g., SETTINGS and DATA may be the same on some platforms), so you
+// should be sure your filenames are unique if you need to avoid naming
+// collisions. Also, a returned path may not actually exist on the file system.
+func GetStandardPath(id StandardPath) (string, error) {
+	path := C.al_get_standard_path(C.int(id))
+	if path == nil {
+		return "", fmt.Errorf("failed to get standard path %d", id)
+	}
+	defer C.al_destroy_path(path)
+	return pathStr(path), nil
+}
+
+type SystemID int
+
+const (
+	SYSTEM_ID_UNKNOWN     SystemID = C.ALLEGRO_SYSTEM_ID_UNKNOWN
+	SYSTEM_ID_XGLX                 = C.ALLEGRO_SYSTEM_ID_XGLX
+	SYSTEM_ID_WINDOWS              = C.ALLEGRO_SYSTEM_ID_WINDOWS
+	SYSTEM_ID_MACOSX               = C.ALLEGRO_SYSTEM_ID_MACOSX
+	SYSTEM_ID_ANDROID              = C.ALLEGRO_SYSTEM_ID_ANDROID
+	SYSTEM_ID_IPHONE               = C.ALLEGRO_SYSTEM_ID_IPHONE
+	SYSTEM_ID_GP2XWIZ              = C.ALLEGRO_SYSTEM_ID_GP2XWIZ
+	SYSTEM_ID_RASPBERRYPI          = C.ALLEGRO_SYSTEM_ID_RASPBERRYPI
+	SYSTEM_ID_SDL                  = C.ALLEGRO_SYSTEM_ID_SDL
+)
+
+func (id SystemID) String() string {
+	switch id {
+	case SYSTEM_ID_XGLX:
+		return "X11/GLX"
+	case SYSTEM_ID_WINDOWS:
+		return "Windows"
+	case SYSTEM_ID_MACOSX:
+		return "macOS"
+	case SYSTEM_ID_ANDROID:
+		return "Android"
+	case SYSTEM_ID_IPHONE:
+		return "iOS"
+	case SYSTEM_ID_GP2XWIZ:
+		return "GP2X Wiz"
+	case SYSTEM_ID_RASPBERRYPI:
+		return "Raspberry Pi"
+	case SYSTEM_ID_SDL:
+		return "SDL"
+	}
+	return "unknown"
+}
+
+// Returns the number of CPUs available on the system. Returns 0 if it could
+// not be determined.
+func CPUCount() int {
+	return int(C.al_get_cpu_count())
+}
+
+// Returns the size in MB of the random access memory that the system has
+// access to. Returns 0 if it could not be determined.
+func RAMSize() int {
+	return int(C.al_get_ram_size())
+}
+
+// Returns the platform that Allegro is running on.
+func GetSystemID() SystemID {
+	return SystemID(C.al_get_system_id())
+}
+
+// Returns true if Allegro is initialized, otherwise returns false.
+func IsSystemInstalled() bool {
+	return bool(C.al_is_system_installed())
+}
+
+// SystemInformation describes the system Allegro is running on.
+type SystemInformation struct {
+	ID       SystemID
+	CPUCount int
+	RAMSize  int // in MB
+	Version  string
+}
+
+// SystemInfo() collects what Allegro knows about the system. The ID is only
+// known once Allegro is initialized.
+func SystemInfo() SystemInformation {
+	major, minor, revision, release := Version()
+	return SystemInformation{
+		ID:       GetSystemID(),
+		CPUCount: CPUCount(),
+		RAMSize:  RAMSize(),
+		Version:  fmt.Sprintf("%d.%d.%d.%d", major, minor, revision, release),
+	}
+}
+
+/* -- Initialization -- */
+
+type initConfig struct {
+	atexit bool
+	values []configValue
+}
+
+type configValue struct {
+	section, key, value string
+}
+
+// InitOption configures Init().
+type InitOption func(*initConfig)
+
+// NoAtExit() stops Init() from registering al_uninstall_system() with C's
+// atexit(), which al_init() does. Go programs don't run atexit() handlers
+// when they exit through os.Exit(), so Shutdown() should be called either way.
+func NoAtExit() InitOption {
+	return func(c *initConfig) { c.atexit = false }
+}
+
+// WithSystemConfig() sets a value in the system config, on top of what
+// allegro5.cfg says, as soon as the system driver is installed. Drivers read
+// their settings when they are installed, so this can select them:
+//
+//	allegro.Init(allegro.WithSystemConfig("graphics", "driver", "opengl"))
+func WithSystemConfig(section, key, value string) InitOption {
+	return func(c *initConfig) {
+		c.values = append(c.values, configValue{section, key, value})
+	}
+}
+
+// WithGraphicsDriver() selects the display driver, e.g. "opengl" or
+// "direct3d" on Windows; see WithSystemConfig().
+func WithGraphicsDriver(driver string) InitOption {
+	return WithSystemConfig("graphics", "driver", driver)
+}
+
+// WithAudioDriver() selects the audio driver used by audio.Install(), e.g.
+// "pulseaudio", "alsa" or "openal"; see WithSystemConfig().
+func WithAudioDriver(driver string) InitOption {
+	return WithSystemConfig("audio", "driver", driver)
+}
+
+// Init() initializes Allegro on the calling thread, as an alternative to
+// Run() for programs, and test binaries, that can't hand over main(). The
+// calling goroutine is locked to its thread until Shutdown(), and becomes the
+// main thread for Do() and friends; it has to call RunPending(), or wait on an
+// event queue, for work sent from other goroutines to run. On macOS, Allegro
+// only works when initialized on the process's main thread, so it should be
+// called from main() or TestMain(), with runtime.LockOSThread() called from an
+// init() function to keep them there.
+//
+//	func TestMain(m *testing.M) {
+//		if err := allegro.Init(); err != nil {
+//			log.Fatal(err)
+//		}
+//		code := m.Run()
+//		allegro.Shutdown()
+//		os.Exit(code)
+//	}
+func Init(opts ...InitOption) error {
+	if currentSession() != 0 {
+		return errors.New("allegro is already initialized")
+	}
+	runtime.LockOSThread()
+	if err := install(opts...); err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	startDispatcher()
+	return nil
+}
+
+// Shutdown() shuts down Allegro after Init(), destroying everything that is
+// left over, and unlocks the calling goroutine from its thread. It has to be
+// called on the same goroutine as Init().
+func Shutdown() {
+	stopDispatcher()
+	uninstall()
+	runtime.UnlockOSThread()
+}
+
+func install(opts ...InitOption) error {
+	c := initConfig{atexit: true}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	C.al_set_errno(0)
+	if !bool(C.install_system(C.bool(c.atexit))) {
+		return NewError("", "install system", "", nil)
+	}
+	if len(c.values) > 0 {
+		cfg := (*Config)(C.al_get_system_config())
+		for _, v := range c.values {
+			cfg.SetValue(v.section, v.key, v.value)
+		}
 	}
 	return nil
 }
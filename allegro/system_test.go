@@ -0,0 +1,32 @@
+package allegro
+
+import "testing"
+
+func TestInitShutdown(t *testing.T) {
+	if err := Init(WithGraphicsDriver("opengl"), NoAtExit()); err != nil {
+		t.Fatal(err)
+	}
+	if !IsSystemInstalled() || !OnMainThread() {
+		t.Error("Allegro isn't running on this thread after Init()")
+	}
+	if err := Init(); err == nil {
+		t.Error("second Init() succeeded")
+	}
+	if info := SystemInfo(); info.CPUCount < 1 || info.ID == SYSTEM_ID_UNKNOWN {
+		t.Errorf("system info is %+v", info)
+	}
+	cfg, err := SystemConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := cfg.Value("graphics", "driver"); err != nil || v != "opengl" {
+		t.Errorf("graphics.driver = '%s' (%v)", v, err)
+	}
+
+	var ran bool
+	Do(func() { ran = true })
+	Shutdown()
+	if !ran || IsSystemInstalled() {
+		t.Errorf("ran = %v, installed = %v after Shutdown()", ran, IsSystemInstalled())
+	}
+}
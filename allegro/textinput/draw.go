@@ -0,0 +1,102 @@
+package textinput
+
+import (
+	"strings"
+
+	"github.com/phrasz/nag/allegro"
+	"github.com/phrasz/nag/allegro/font"
+	"github.com/phrasz/nag/allegro/primitives"
+)
+
+// Measurer measures the width of text. *font.Font implements it.
+type Measurer interface {
+	TextWidth(text string) int
+}
+
+// Display() returns the text as it is shown, with every character replaced by
+// the mask if one is set.
+func (f *Field) Display() string {
+	if f.Mask == 0 {
+		return string(f.text)
+	}
+	return strings.Repeat(string(f.Mask), len(f.text))
+}
+
+// offset() returns the distance from the start of the text to a position.
+func (f *Field) offset(m Measurer, pos int) float32 {
+	if f.Mask != 0 {
+		return float32(m.TextWidth(strings.Repeat(string(f.Mask), pos)))
+	}
+	return float32(m.TextWidth(string(f.text[:pos])))
+}
+
+// CaretX() returns the distance from the start of the text to the caret.
+func (f *Field) CaretX(m Measurer) float32 {
+	return f.offset(m, f.caret)
+}
+
+// Scroll() returns how far the text is scrolled to the left.
+func (f *Field) Scroll() float32 {
+	return f.scroll
+}
+
+// ScrollTo() scrolls the text just far enough for the caret to be visible in
+// a field of the given width, and returns the new scroll offset.
+func (f *Field) ScrollTo(m Measurer, width float32) float32 {
+	x := f.CaretX(m)
+	if x-f.scroll > width-1 {
+		f.scroll = x - width + 1
+	}
+	if x < f.scroll {
+		f.scroll = x
+	}
+	// Don't leave empty space on the right once text has been deleted.
+	if total := f.offset(m, len(f.text)); f.scroll > 0 && total-f.scroll < width-1 {
+		f.scroll = total - width + 1
+	}
+	if f.scroll < 0 {
+		f.scroll = 0
+	}
+	return f.scroll
+}
+
+// PositionAt() returns the position closest to x, measured from the left edge
+// of the field, taking the scroll offset into account. It can be used to move
+// the caret with the mouse.
+func (f *Field) PositionAt(m Measurer, x float32) int {
+	x += f.scroll
+	prev := float32(0)
+	for pos := 1; pos <= len(f.text); pos++ {
+		next := f.offset(m, pos)
+		if x < (prev+next)/2 {
+			return pos - 1
+		}
+		prev = next
+	}
+	return len(f.text)
+}
+
+// Draw() draws the field into a box at x, y of the given width, as tall as a
+// line of the font, scrolling the text to keep the caret in view. The caret
+// is drawn if showCaret is true, so that callers can blink it or hide it when
+// the field doesn't have focus.
+func (f *Field) Draw(fnt *font.Font, textColor, selectionColor allegro.Color, x, y, width float32, showCaret bool) {
+	height := float32(fnt.LineHeight())
+	scroll := f.ScrollTo(fnt, width)
+
+	cx, cy, cw, ch := allegro.ClippingRectangle()
+	defer allegro.SetClippingRectangle(cx, cy, cw, ch)
+	allegro.SetClippingRectangle(int(x), int(y), int(width), int(height))
+
+	if f.HasSelection() {
+		start, end := f.Selection()
+		x1 := x - scroll + f.offset(fnt, start)
+		x2 := x - scroll + f.offset(fnt, end)
+		primitives.DrawFilledRectangle(allegro.Point{X: x1, Y: y}, allegro.Point{X: x2, Y: y + height}, selectionColor)
+	}
+	font.DrawText(fnt, textColor, x-scroll, y, font.ALIGN_LEFT, f.Display())
+	if showCaret {
+		caret := x - scroll + f.CaretX(fnt) + 0.5
+		primitives.DrawLine(allegro.Point{X: caret, Y: y}, allegro.Point{X: caret, Y: y + height}, textColor, 1)
+	}
+}
@@ -0,0 +1,480 @@
+// Package textinput provides a single line text field, with a caret and
+// selection, word navigation, undo and redo, clipboard support and masking
+// for passwords.
+//
+// A Field is fed KeyCharEvents through Handle(), which takes care of both
+// editing keys and typed characters, including those composed by an input
+// method. Everything except Draw() works without a display, so the editing
+// logic can be tested with synthetic events.
+package textinput
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+// Clipboard is where cut and copied text goes. *allegro.Display implements
+// it.
+type Clipboard interface {
+	ClipboardText() (string, error)
+	SetClipboardText(text string) error
+}
+
+type editKind int
+
+const (
+	editOther editKind = iota
+	editTyping
+)
+
+// snapshot is the state that undo and redo go back and forth between.
+type snapshot struct {
+	text          []rune
+	caret, anchor int
+}
+
+// Field is a single line of editable text. The caret and selection are
+// positions between characters, counted in runes.
+type Field struct {
+	// Mask, if set, is shown in place of every character, and stops text
+	// from being copied out of the field.
+	Mask rune
+
+	// MaxLength limits the number of characters in the field, if set.
+	MaxLength int
+
+	// Clipboard is used for cut, copy and paste. They do nothing if it is
+	// nil.
+	Clipboard Clipboard
+
+	// OnError, if set, is called with the error when a cut, copy or paste
+	// done by Handle() fails, e.g. because the clipboard holds no text.
+	OnError func(err error)
+
+	text          []rune
+	caret, anchor int
+	scroll        float32
+
+	undo, redo []snapshot
+	lastEdit   editKind
+}
+
+// New() creates an empty field.
+func New() *Field {
+	return &Field{}
+}
+
+// Text() returns the contents of the field.
+func (f *Field) Text() string {
+	return string(f.text)
+}
+
+// SetText() replaces the contents of the field, moves the caret to the end
+// and clears the undo history.
+func (f *Field) SetText(text string) {
+	f.text = []rune(text)
+	if f.MaxLength > 0 && len(f.text) > f.MaxLength {
+		f.text = f.text[:f.MaxLength]
+	}
+	f.caret, f.anchor = len(f.text), len(f.text)
+	f.undo, f.redo = nil, nil
+	f.lastEdit = editOther
+}
+
+// Len() returns the number of characters in the field.
+func (f *Field) Len() int {
+	return len(f.text)
+}
+
+// Caret() returns the position of the caret.
+func (f *Field) Caret() int {
+	return f.caret
+}
+
+// SetCaret() moves the caret, extending the selection if selecting is true
+// and clearing it otherwise.
+func (f *Field) SetCaret(pos int, selecting bool) {
+	f.caret = f.clamp(pos)
+	if !selecting {
+		f.anchor = f.caret
+	}
+	f.lastEdit = editOther
+}
+
+// Selection() returns the start and end of the selection. They are equal when
+// nothing is selected.
+func (f *Field) Selection() (start, end int) {
+	if f.anchor < f.caret {
+		return f.anchor, f.caret
+	}
+	return f.caret, f.anchor
+}
+
+// HasSelection() returns true if any text is selected.
+func (f *Field) HasSelection() bool {
+	return f.anchor != f.caret
+}
+
+// SelectedText() returns the selected text.
+func (f *Field) SelectedText() string {
+	start, end := f.Selection()
+	return string(f.text[start:end])
+}
+
+// SelectAll() selects the whole contents of the field.
+func (f *Field) SelectAll() {
+	f.anchor, f.caret = 0, len(f.text)
+	f.lastEdit = editOther
+}
+
+/* -- Editing -- */
+
+func (f *Field) save(kind editKind) {
+	if kind != editTyping || f.lastEdit != editTyping {
+		f.undo = append(f.undo, snapshot{append([]rune(nil), f.text...), f.caret, f.anchor})
+	}
+	f.redo = nil
+	f.lastEdit = kind
+}
+
+// replace() replaces the text from start to end, and leaves the caret after
+// the replacement.
+func (f *Field) replace(start, end int, text []rune, kind editKind) {
+	if f.MaxLength > 0 {
+		if room := f.MaxLength - (len(f.text) - (end - start)); len(text) > room {
+			if room < 0 {
+				room = 0
+			}
+			text = text[:room]
+		}
+	}
+	if len(text) == 0 && start == end {
+		return
+	}
+	f.save(kind)
+	rest := append(text, f.text[end:]...)
+	f.text = append(f.text[:start], rest...)
+	f.caret = start + len(text)
+	f.anchor = f.caret
+}
+
+func (f *Field) replaceSelection(text []rune, kind editKind) {
+	start, end := f.Selection()
+	f.replace(start, end, text, kind)
+}
+
+// Insert() replaces the selection with text, or inserts it at the caret if
+// nothing is selected. Line breaks and other control characters are dropped.
+func (f *Field) Insert(text string) {
+	runes := make([]rune, 0, utf8.RuneCountInString(text))
+	for _, r := range text {
+		if !unicode.IsControl(r) {
+			runes = append(runes, r)
+		}
+	}
+	f.replaceSelection(runes, editOther)
+}
+
+// typeRune() inserts a single typed character. Runs of typed characters are
+// undone together, a word at a time.
+func (f *Field) typeRune(r rune) {
+	kind := editTyping
+	if unicode.IsSpace(r) || f.HasSelection() {
+		kind = editOther
+	}
+	f.replaceSelection([]rune{r}, kind)
+	if kind == editOther && unicode.IsSpace(r) {
+		// The space starts a new undo step, which the word after it
+		// should join.
+		f.lastEdit = editTyping
+	}
+}
+
+// deleteTo() deletes the selection if there is one, and the text between the
+// caret and pos otherwise.
+func (f *Field) deleteTo(pos int) {
+	if f.HasSelection() {
+		f.replaceSelection(nil, editOther)
+		return
+	}
+	pos = f.clamp(pos)
+	if pos < f.caret {
+		f.replace(pos, f.caret, nil, editOther)
+	} else {
+		f.replace(f.caret, pos, nil, editOther)
+	}
+}
+
+func (f *Field) clamp(pos int) int {
+	if pos < 0 {
+		return 0
+	}
+	if pos > len(f.text) {
+		return len(f.text)
+	}
+	return pos
+}
+
+// Backspace() deletes the selection, or the character before the caret.
+func (f *Field) Backspace() {
+	f.deleteTo(f.caret - 1)
+}
+
+// Delete() deletes the selection, or the character after the caret.
+func (f *Field) Delete() {
+	if !f.HasSelection() && f.caret == len(f.text) {
+		return
+	}
+	f.deleteTo(f.caret + 1)
+}
+
+// DeleteWordLeft() deletes the selection, or back to the start of the word
+// before the caret.
+func (f *Field) DeleteWordLeft() {
+	f.deleteTo(f.wordLeft())
+}
+
+// DeleteWordRight() deletes the selection, or up to the end of the word after
+// the caret.
+func (f *Field) DeleteWordRight() {
+	f.deleteTo(f.wordRight())
+}
+
+/* -- Navigation -- */
+
+func (f *Field) isWord(i int) bool {
+	if f.Mask != 0 {
+		// Masked text is treated as a single word, so as not to give
+		// away where its spaces are.
+		return true
+	}
+	r := f.text[i]
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// wordLeft() returns the start of the word before the caret.
+func (f *Field) wordLeft() int {
+	i := f.caret
+	for i > 0 && !f.isWord(i-1) {
+		i--
+	}
+	for i > 0 && f.isWord(i-1) {
+		i--
+	}
+	return i
+}
+
+// wordRight() returns the end of the word after the caret.
+func (f *Field) wordRight() int {
+	i := f.caret
+	for i < len(f.text) && !f.isWord(i) {
+		i++
+	}
+	for i < len(f.text) && f.isWord(i) {
+		i++
+	}
+	return i
+}
+
+// Left() moves the caret one character left. Without selecting, it collapses
+// an existing selection to its start instead.
+func (f *Field) Left(selecting bool) {
+	if start, _ := f.Selection(); f.HasSelection() && !selecting {
+		f.SetCaret(start, false)
+		return
+	}
+	f.SetCaret(f.caret-1, selecting)
+}
+
+// Right() moves the caret one character right. Without selecting, it
+// collapses an existing selection to its end instead.
+func (f *Field) Right(selecting bool) {
+	if _, end := f.Selection(); f.HasSelection() && !selecting {
+		f.SetCaret(end, false)
+		return
+	}
+	f.SetCaret(f.caret+1, selecting)
+}
+
+// WordLeft() moves the caret to the start of the previous word.
+func (f *Field) WordLeft(selecting bool) {
+	f.SetCaret(f.wordLeft(), selecting)
+}
+
+// WordRight() moves the caret to the end of the next word.
+func (f *Field) WordRight(selecting bool) {
+	f.SetCaret(f.wordRight(), selecting)
+}
+
+// Home() moves the caret to the start of the field.
+func (f *Field) Home(selecting bool) {
+	f.SetCaret(0, selecting)
+}
+
+// End() moves the caret to the end of the field.
+func (f *Field) End(selecting bool) {
+	f.SetCaret(len(f.text), selecting)
+}
+
+/* -- Undo -- */
+
+// Undo() reverts the last edit, returning false if there was nothing to undo.
+func (f *Field) Undo() bool {
+	if len(f.undo) == 0 {
+		return false
+	}
+	f.redo = append(f.redo, snapshot{f.text, f.caret, f.anchor})
+	s := f.undo[len(f.undo)-1]
+	f.undo = f.undo[:len(f.undo)-1]
+	f.text, f.caret, f.anchor = s.text, s.caret, s.anchor
+	f.lastEdit = editOther
+	return true
+}
+
+// Redo() reapplies the last edit that was undone, returning false if there
+// was nothing to redo.
+func (f *Field) Redo() bool {
+	if len(f.redo) == 0 {
+		return false
+	}
+	f.undo = append(f.undo, snapshot{f.text, f.caret, f.anchor})
+	s := f.redo[len(f.redo)-1]
+	f.redo = f.redo[:len(f.redo)-1]
+	f.text, f.caret, f.anchor = s.text, s.caret, s.anchor
+	f.lastEdit = editOther
+	return true
+}
+
+/* -- Clipboard -- */
+
+// Copy() puts the selected text on the clipboard. Masked fields can't be
+// copied from.
+func (f *Field) Copy() error {
+	if f.Clipboard == nil || f.Mask != 0 || !f.HasSelection() {
+		return nil
+	}
+	return f.Clipboard.SetClipboardText(f.SelectedText())
+}
+
+// Cut() puts the selected text on the clipboard, and deletes it.
+func (f *Field) Cut() error {
+	if f.Clipboard == nil || f.Mask != 0 || !f.HasSelection() {
+		return nil
+	}
+	if err := f.Copy(); err != nil {
+		return err
+	}
+	f.replaceSelection(nil, editOther)
+	return nil
+}
+
+// Paste() replaces the selection with the text on the clipboard.
+func (f *Field) Paste() error {
+	if f.Clipboard == nil {
+		return nil
+	}
+	text, err := f.Clipboard.ClipboardText()
+	if err != nil {
+		return err
+	}
+	f.Insert(text)
+	return nil
+}
+
+// report() passes a clipboard error on to OnError.
+func (f *Field) report(err error) {
+	if err != nil && f.OnError != nil {
+		f.OnError(err)
+	}
+}
+
+/* -- Events -- */
+
+// Handle() applies a KeyCharEvent to the field, and returns true if it was
+// used. The usual editing keys are understood, with Ctrl (or Command) for
+// word-wise movement and shortcuts, and Shift for selecting. Keys that the
+// field has no use for, such as Enter, Tab and Escape, are left to the
+// caller. Other events are ignored. Errors from the clipboard shortcuts are
+// passed to OnError.
+func (f *Field) Handle(e interface{}) bool {
+	ev, ok := e.(allegro.KeyCharEvent)
+	if !ok {
+		return false
+	}
+	mods := ev.Modifiers()
+	selecting := mods&allegro.KEYMOD_SHIFT != 0
+	command := mods&allegro.KEYMOD_CTRL != 0 || mods&allegro.KEYMOD_COMMAND != 0
+
+	switch ev.KeyCode() {
+	case allegro.KEY_LEFT:
+		if command {
+			f.WordLeft(selecting)
+		} else {
+			f.Left(selecting)
+		}
+		return true
+	case allegro.KEY_RIGHT:
+		if command {
+			f.WordRight(selecting)
+		} else {
+			f.Right(selecting)
+		}
+		return true
+	case allegro.KEY_HOME:
+		f.Home(selecting)
+		return true
+	case allegro.KEY_END:
+		f.End(selecting)
+		return true
+	case allegro.KEY_BACKSPACE:
+		if command {
+			f.DeleteWordLeft()
+		} else {
+			f.Backspace()
+		}
+		return true
+	case allegro.KEY_DELETE:
+		if command {
+			f.DeleteWordRight()
+		} else {
+			f.Delete()
+		}
+		return true
+	}
+
+	if command && mods&allegro.KEYMOD_ALT == 0 {
+		switch ev.KeyCode() {
+		case allegro.KEY_A:
+			f.SelectAll()
+		case allegro.KEY_Z:
+			if selecting {
+				f.Redo()
+			} else {
+				f.Undo()
+			}
+		case allegro.KEY_Y:
+			f.Redo()
+		case allegro.KEY_C:
+			f.report(f.Copy())
+		case allegro.KEY_X:
+			f.report(f.Cut())
+		case allegro.KEY_V:
+			f.report(f.Paste())
+		default:
+			return false
+		}
+		return true
+	}
+
+	// Anything else that carries a printable character is typed. This
+	// includes characters composed by an input method, which may arrive
+	// without a key code.
+	r := rune(ev.Unichar())
+	if r <= 0 || unicode.IsControl(r) || !utf8.ValidRune(r) {
+		return false
+	}
+	f.typeRune(r)
+	return true
+}
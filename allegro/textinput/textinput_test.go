@@ -0,0 +1,190 @@
+package textinput
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/phrasz/nag/allegro"
+)
+
+var event allegro.Event
+
+func typeText(f *Field, text string) {
+	for _, r := range text {
+		f.Handle(event.SetKeyChar(0, 0, int(r), 0, false, nil))
+	}
+}
+
+func press(f *Field, k allegro.KeyCode, mods allegro.KeyModifier) bool {
+	return f.Handle(event.SetKeyChar(0, k, 0, mods, false, nil))
+}
+
+func check(t *testing.T, f *Field, text string, caret int) {
+	t.Helper()
+	if f.Text() != text || f.Caret() != caret {
+		t.Errorf("field is '%s' with caret at %d, want '%s' at %d", f.Text(), f.Caret(), text, caret)
+	}
+}
+
+// monospace measures every character as 10 wide.
+type monospace struct{}
+
+func (monospace) TextWidth(text string) int {
+	return 10 * utf8.RuneCountInString(text)
+}
+
+type fakeClipboard struct {
+	text string
+}
+
+func (c *fakeClipboard) ClipboardText() (string, error) {
+	if c.text == "" {
+		return "", errors.New("failed to get clipboard text")
+	}
+	return c.text, nil
+}
+
+func (c *fakeClipboard) SetClipboardText(text string) error {
+	c.text = text
+	return nil
+}
+
+func TestTyping(t *testing.T) {
+	f := New()
+	typeText(f, "héllo wörld")
+	check(t, f, "héllo wörld", 11)
+
+	press(f, allegro.KEY_LEFT, allegro.KEYMOD_CTRL)
+	check(t, f, "héllo wörld", 6)
+	press(f, allegro.KEY_BACKSPACE, 0)
+	check(t, f, "héllowörld", 5)
+	press(f, allegro.KEY_HOME, 0)
+	press(f, allegro.KEY_DELETE, 0)
+	check(t, f, "éllowörld", 0)
+	press(f, allegro.KEY_BACKSPACE, 0)
+	check(t, f, "éllowörld", 0)
+
+	// Enter is left to the caller, and so are control characters.
+	if f.Handle(event.SetKeyChar(0, allegro.KEY_ENTER, '\r', 0, false, nil)) {
+		t.Error("Enter was consumed")
+	}
+	check(t, f, "éllowörld", 0)
+}
+
+func TestSelection(t *testing.T) {
+	f := New()
+	typeText(f, "one two three")
+	press(f, allegro.KEY_LEFT, allegro.KEYMOD_CTRL|allegro.KEYMOD_SHIFT)
+	if s := f.SelectedText(); s != "three" {
+		t.Errorf("selected '%s', want 'three'", s)
+	}
+	typeText(f, "3")
+	check(t, f, "one two 3", 9)
+
+	press(f, allegro.KEY_A, allegro.KEYMOD_CTRL)
+	press(f, allegro.KEY_LEFT, 0)
+	check(t, f, "one two 3", 0)
+	press(f, allegro.KEY_RIGHT, allegro.KEYMOD_SHIFT)
+	press(f, allegro.KEY_RIGHT, allegro.KEYMOD_SHIFT)
+	if start, end := f.Selection(); start != 0 || end != 2 {
+		t.Errorf("selection is %d-%d, want 0-2", start, end)
+	}
+	press(f, allegro.KEY_DELETE, 0)
+	check(t, f, "e two 3", 0)
+}
+
+func TestUndo(t *testing.T) {
+	f := New()
+	typeText(f, "hello world")
+	press(f, allegro.KEY_Z, allegro.KEYMOD_CTRL)
+	check(t, f, "hello", 5)
+	press(f, allegro.KEY_Z, allegro.KEYMOD_CTRL)
+	check(t, f, "", 0)
+	if f.Undo() {
+		t.Error("undid past the start of the history")
+	}
+	press(f, allegro.KEY_Y, allegro.KEYMOD_CTRL)
+	press(f, allegro.KEY_Z, allegro.KEYMOD_CTRL|allegro.KEYMOD_SHIFT)
+	check(t, f, "hello world", 11)
+
+	press(f, allegro.KEY_BACKSPACE, allegro.KEYMOD_CTRL)
+	check(t, f, "hello ", 6)
+	typeText(f, "x")
+	if f.Redo() {
+		t.Error("redo history survived a new edit")
+	}
+	f.Undo()
+	f.Undo()
+	check(t, f, "hello world", 11)
+}
+
+func TestClipboard(t *testing.T) {
+	clip := &fakeClipboard{}
+	f := New()
+	f.Clipboard = clip
+	typeText(f, "copy me")
+	press(f, allegro.KEY_LEFT, allegro.KEYMOD_CTRL|allegro.KEYMOD_SHIFT)
+	press(f, allegro.KEY_X, allegro.KEYMOD_CTRL)
+	check(t, f, "copy ", 5)
+	press(f, allegro.KEY_HOME, 0)
+	press(f, allegro.KEY_V, allegro.KEYMOD_COMMAND)
+	check(t, f, "mecopy ", 2)
+
+	clip.text = "line\none"
+	f.Paste()
+	check(t, f, "melineonecopy ", 9)
+
+	f.Mask = '*'
+	f.SelectAll()
+	clip.text = "unchanged"
+	if err := f.Copy(); err != nil || clip.text != "unchanged" {
+		t.Errorf("copied '%s' out of a masked field", clip.text)
+	}
+
+	// Failures of the shortcuts are reported, rather than lost.
+	var errs []error
+	f.OnError = func(err error) { errs = append(errs, err) }
+	clip.text = ""
+	press(f, allegro.KEY_V, allegro.KEYMOD_CTRL)
+	if len(errs) != 1 {
+		t.Errorf("pasting from an empty clipboard reported %v", errs)
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	f := New()
+	f.MaxLength = 4
+	typeText(f, "abcdef")
+	check(t, f, "abcd", 4)
+	f.SelectAll()
+	f.Insert("123456")
+	check(t, f, "1234", 4)
+}
+
+func TestMeasure(t *testing.T) {
+	f := New()
+	f.Mask = '*'
+	typeText(f, "secret password")
+	if d := f.Display(); d != "***************" {
+		t.Errorf("masked text shown as '%s'", d)
+	}
+	// Masked text is a single word.
+	press(f, allegro.KEY_LEFT, allegro.KEYMOD_CTRL)
+	check(t, f, "secret password", 0)
+
+	f.End(false)
+	if s := f.ScrollTo(monospace{}, 100); s != 51 {
+		t.Errorf("scrolled to %v, want 51", s)
+	}
+	if pos := f.PositionAt(monospace{}, 3); pos != 5 {
+		t.Errorf("position at 3 is %d, want 5", pos)
+	}
+	f.Home(false)
+	if s := f.ScrollTo(monospace{}, 100); s != 0 {
+		t.Errorf("scrolled to %v at the start, want 0", s)
+	}
+	if pos := f.PositionAt(monospace{}, 16); pos != 2 {
+		t.Errorf("position at 16 is %d, want 2", pos)
+	}
+}
@@ -0,0 +1,223 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+// #include <stdint.h>
+/*
+extern void *go_thread_proc(ALLEGRO_THREAD *thread, uintptr_t arg);
+
+static void *thread_proc(ALLEGRO_THREAD *thread, void *arg) {
+	return go_thread_proc(thread, (uintptr_t)arg);
+}
+
+static ALLEGRO_THREAD *create_thread(uintptr_t arg) {
+	return al_create_thread(thread_proc, (void *)arg);
+}
+
+static void run_detached_thread(uintptr_t arg) {
+	al_run_detached_thread(thread_proc, (void *)arg);
+}
+*/
+import "C"
+import (
+	"runtime"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+type Thread C.ALLEGRO_THREAD
+
+type Mutex C.ALLEGRO_MUTEX
+
+type Cond C.ALLEGRO_COND
+
+// threadProc is what gets passed through Allegro as a thread's argument. For
+// threads created with CreateThread the handle is released on Destroy, since
+// the thread may be started (and its function called) at any time before that.
+type threadProc struct {
+	f        func(*Thread)
+	detached bool
+}
+
+// threadHandles keeps track of the handle belonging to each thread created by
+// CreateThread, so that it can be released when the thread is destroyed.
+var (
+	threadHandles     = make(map[*Thread]cgo.Handle)
+	threadHandlesLock sync.Mutex
+)
+
+//export go_thread_proc
+func go_thread_proc(thread *C.ALLEGRO_THREAD, arg C.uintptr_t) unsafe.Pointer {
+	h := cgo.Handle(arg)
+	proc := h.Value().(*threadProc)
+	if proc.detached {
+		h.Delete()
+	}
+	proc.f((*Thread)(thread))
+	return nil
+}
+
+// Spawn a new thread which begins executing f. The new thread is passed its
+// own thread handle, which it can use to check ShouldStop().
+//
+// The thread is created in a suspended state; call Start() to run it.
+func CreateThread(f func(*Thread)) (*Thread, error) {
+	h := cgo.NewHandle(&threadProc{f: f})
+	t := C.create_thread(C.uintptr_t(h))
+	if t == nil {
+		h.Delete()
+		return nil, &Error{Op: "create thread"}
+	}
+	threadHandlesLock.Lock()
+	threadHandles[(*Thread)(t)] = h
+	threadHandlesLock.Unlock()
+	return (*Thread)(t), nil
+}
+
+// Runs the passed function in its own thread. This is similar to calling al_create_thread, al_start_thread and
+// (after the thread has finished) al_destroy_thread - but you don't have the
+// possibility of ever calling al_join_thread on the thread.
+func RunDetachedThread(f func()) {
+	h := cgo.NewHandle(&threadProc{f: func(*Thread) { f() }, detached: true})
+	C.run_detached_thread(C.uintptr_t(h))
+}
+
+// RunThreadWithState() runs f on a new, detached Allegro thread, after first
+// restoring the given state on it. Allegro keeps things like the target
+// bitmap and the new bitmap flags per thread, so this is the way to hand the
+// relevant parts of the current thread's state (as captured by StoreState())
+// over to background work, such as loading bitmaps with a different set of
+// new bitmap flags. The returned channel is closed once f returns.
+func RunThreadWithState(state *State, f func()) <-chan struct{} {
+	done := make(chan struct{})
+	s := *state
+	RunDetachedThread(func() {
+		defer close(done)
+		RestoreState(&s)
+		f()
+	})
+	return done
+}
+
+// When a thread has been created, it is initially in a suspended state.
+// Calling al_start_thread will start its actual execution.
+func (t *Thread) Start() {
+	C.al_start_thread((*C.ALLEGRO_THREAD)(t))
+}
+
+// Wait for the thread to finish executing. This implicitly calls
+// al_set_thread_should_stop first.
+func (t *Thread) Join() {
+	C.al_join_thread((*C.ALLEGRO_THREAD)(t), nil)
+}
+
+// Set the flag to indicate thread should stop. Returns immediately.
+func (t *Thread) SetShouldStop() {
+	C.al_set_thread_should_stop((*C.ALLEGRO_THREAD)(t))
+}
+
+// Check if another thread is waiting for thread to stop. Threads which run in
+// a loop should check this periodically and act on it when convenient.
+func (t *Thread) ShouldStop() bool {
+	return bool(C.al_get_thread_should_stop((*C.ALLEGRO_THREAD)(t)))
+}
+
+// Free the resources used by a thread. Implicitly performs al_join_thread on
+// the thread if it hasn't been done already.
+func (t *Thread) Destroy() {
+	C.al_destroy_thread((*C.ALLEGRO_THREAD)(t))
+	threadHandlesLock.Lock()
+	defer threadHandlesLock.Unlock()
+	if h, ok := threadHandles[t]; ok {
+		h.Delete()
+		delete(threadHandles, t)
+	}
+}
+
+// Create the mutex object (a mutual exclusion device). The mutex may or may
+// not support "recursive" locking.
+func CreateMutex() (*Mutex, error) {
+	m := C.al_create_mutex()
+	if m == nil {
+		return nil, &Error{Op: "create mutex"}
+	}
+	return (*Mutex)(m), nil
+}
+
+// Create the mutex object (a mutual exclusion device), with support for
+// "recursive" locking. That is, the mutex will count the number of times it
+// has been locked by the same thread. If the caller tries to acquire a lock on
+// the mutex when it already holds the lock then the count is incremented. The
+// mutex is only unlocked when the thread releases the lock on the mutex an
+// equal number of times, i.e. the count drops down to zero.
+func CreateMutexRecursive() (*Mutex, error) {
+	m := C.al_create_mutex_recursive()
+	if m == nil {
+		return nil, &Error{Op: "create recursive mutex"}
+	}
+	return (*Mutex)(m), nil
+}
+
+// Acquire the lock on mutex. If the mutex is already locked by another thread,
+// the call will block until the mutex becomes available and locked.
+//
+// The mutex belongs to the thread that locked it, so the calling goroutine is
+// locked to its thread until the matching Unlock().
+func (m *Mutex) Lock() {
+	runtime.LockOSThread()
+	C.al_lock_mutex((*C.ALLEGRO_MUTEX)(m))
+}
+
+// Release the lock on mutex if the calling thread holds the lock on it.
+func (m *Mutex) Unlock() {
+	C.al_unlock_mutex((*C.ALLEGRO_MUTEX)(m))
+	runtime.UnlockOSThread()
+}
+
+// Free the resources used by the mutex. The mutex should be unlocked.
+// Destroying a locked mutex results in undefined behaviour.
+func (m *Mutex) Destroy() {
+	C.al_destroy_mutex((*C.ALLEGRO_MUTEX)(m))
+}
+
+// Create a condition variable.
+func CreateCond() (*Cond, error) {
+	c := C.al_create_cond()
+	if c == nil {
+		return nil, &Error{Op: "create condition variable"}
+	}
+	return (*Cond)(c), nil
+}
+
+// Destroy a condition variable.
+func (c *Cond) Destroy() {
+	C.al_destroy_cond((*C.ALLEGRO_COND)(c))
+}
+
+// On entering this function, mutex must be locked by the calling thread. The
+// function will atomically release mutex and block on cond. The function will
+// return when cond is "signalled", acquiring the lock on the mutex in the
+// process. Since Mutex.Lock() keeps the goroutine on its thread, the mutex is
+// released and reacquired by the same thread.
+func (c *Cond) Wait(mutex *Mutex) {
+	C.al_wait_cond((*C.ALLEGRO_COND)(c), (*C.ALLEGRO_MUTEX)(mutex))
+}
+
+// Like al_wait_cond but the call can return if the absolute time passes
+// timeout before the condition is signalled. Returns false if the timeout
+// was reached.
+func (c *Cond) WaitUntil(mutex *Mutex, timeout *Timeout) bool {
+	return C.al_wait_cond_until((*C.ALLEGRO_COND)(c), (*C.ALLEGRO_MUTEX)(mutex), (*C.ALLEGRO_TIMEOUT)(timeout)) == 0
+}
+
+// Unblock all threads currently waiting on a condition variable. That is,
+// broadcast that some condition which those threads were waiting for has
+// become true.
+func (c *Cond) Broadcast() {
+	C.al_broadcast_cond((*C.ALLEGRO_COND)(c))
+}
+
+// Unblock at least one thread waiting on a condition variable.
+func (c *Cond) Signal() {
+	C.al_signal_cond((*C.ALLEGRO_COND)(c))
+}
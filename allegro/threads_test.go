@@ -0,0 +1,55 @@
+package allegro
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestMutexAcrossGoroutines(t *testing.T) {
+	// A recursive mutex can only be unlocked by the thread that locked it, so
+	// this deadlocks if a goroutine moves to another thread while holding it.
+	m, err := CreateMutexRecursive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Destroy()
+	c, err := CreateCond()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Destroy()
+
+	var (
+		wg    sync.WaitGroup
+		count int
+		ready bool
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Lock()
+			for !ready {
+				c.Wait(m)
+			}
+			m.Unlock()
+			for j := 0; j < 100; j++ {
+				m.Lock()
+				m.Lock()
+				count++
+				runtime.Gosched()
+				m.Unlock()
+				m.Unlock()
+			}
+		}()
+	}
+	m.Lock()
+	ready = true
+	c.Broadcast()
+	m.Unlock()
+	wg.Wait()
+	if count != 800 {
+		t.Errorf("count is %d, want 800", count)
+	}
+}
@@ -5,8 +5,9 @@ package tiled
 // #include "../util.c"
 import "C"
 import (
-	"fmt"
 	"path/filepath"
+
+	"github.com/phrasz/nag/allegro"
 )
 
 type Map *C.ALLEGRO_MAP
@@ -20,7 +21,7 @@ func OpenMap(filename string) (*Map, error) {
 	defer C.free_string(dir_)
 	m := C.al_open_map(dir_, base_)
 	if m == nil {
-		return nil, fmt.Errorf("failed to load map file: %s", filename)
+		return nil, &allegro.Error{Op: "load map", Path: filename, Addon: "tiled"}
 	}
 	return (*Map)(m), nil
 }
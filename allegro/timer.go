@@ -2,9 +2,6 @@ package allegro
 
 // #include <allegro5/allegro.h>
 import "C"
-import (
-	"errors"
-)
 
 type Timer C.ALLEGRO_TIMER
 
@@ -14,7 +11,7 @@ type Timer C.ALLEGRO_TIMER
 func CreateTimer(speed float64) (*Timer, error) {
 	t := C.al_create_timer(C.double(speed))
 	if t == nil {
-		return nil, errors.New("failed to create timer")
+		return nil, &Error{Op: "create timer"}
 	}
 	timer := (*Timer)(t)
 	//runtime.SetFinalizer(timer, timer.Destroy)
@@ -0,0 +1,116 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+import "C"
+
+type TouchInput C.ALLEGRO_TOUCH_INPUT
+
+type TouchInputState C.ALLEGRO_TOUCH_INPUT_STATE
+
+type TouchState C.ALLEGRO_TOUCH_STATE
+
+type MouseEmulationMode int
+
+const (
+	MOUSE_EMULATION_NONE        MouseEmulationMode = C.ALLEGRO_MOUSE_EMULATION_NONE
+	MOUSE_EMULATION_TRANSPARENT                    = C.ALLEGRO_MOUSE_EMULATION_TRANSPARENT
+	MOUSE_EMULATION_INCLUSIVE                      = C.ALLEGRO_MOUSE_EMULATION_INCLUSIVE
+	MOUSE_EMULATION_EXCLUSIVE                      = C.ALLEGRO_MOUSE_EMULATION_EXCLUSIVE
+	MOUSE_EMULATION_5_0_x                          = C.ALLEGRO_MOUSE_EMULATION_5_0_x
+)
+
+// Install a touch input driver, returning true if successful. If a touch input
+// driver was already installed, returns true immediately.
+func InstallTouchInput() error {
+	if !bool(C.al_install_touch_input()) {
+		return &Error{Op: "install touch input"}
+	}
+	return nil
+}
+
+// Returns true if al_install_touch_input was called successfully.
+func IsTouchInputInstalled() bool {
+	return bool(C.al_is_touch_input_installed())
+}
+
+// Uninstalls the active touch input driver. If no touch input driver was
+// active, this function does nothing.
+func UninstallTouchInput() {
+	C.al_uninstall_touch_input()
+}
+
+// Get the current touch input state. The touch information is copied into the
+// ALLEGRO_TOUCH_INPUT_STATE structure specified.
+func (state *TouchInputState) Get() {
+	C.al_get_touch_input_state((*C.ALLEGRO_TOUCH_INPUT_STATE)(state))
+}
+
+// Touches() returns the touches that were active in the saved state.
+func (state *TouchInputState) Touches() []*TouchState {
+	var touches []*TouchState
+	for i := range state.touches {
+		if state.touches[i].id >= 0 {
+			touches = append(touches, (*TouchState)(&state.touches[i]))
+		}
+	}
+	return touches
+}
+
+func (state *TouchState) Id() int {
+	return int(state.id)
+}
+
+func (state *TouchState) X() float32 {
+	return float32(state.x)
+}
+
+func (state *TouchState) Y() float32 {
+	return float32(state.y)
+}
+
+func (state *TouchState) Dx() float32 {
+	return float32(state.dx)
+}
+
+func (state *TouchState) Dy() float32 {
+	return float32(state.dy)
+}
+
+func (state *TouchState) Primary() bool {
+	return bool(state.primary)
+}
+
+func (state *TouchState) Display() *Display {
+	return (*Display)(state.display)
+}
+
+// Returns the global touch input event source.
+func TouchInputEventSource() (*EventSource, error) {
+	source := C.al_get_touch_input_event_source()
+	if source == nil {
+		return nil, &Error{Op: "get touch input event source", Err: ErrNotInstalled}
+	}
+	return (*EventSource)(source), nil
+}
+
+// Returns the event source which generates the mouse events emulated from
+// touch input.
+func TouchInputMouseEmulationEventSource() (*EventSource, error) {
+	source := C.al_get_touch_input_mouse_emulation_event_source()
+	if source == nil {
+		return nil, &Error{Op: "get mouse emulation event source", Err: ErrNotInstalled}
+	}
+	return (*EventSource)(source), nil
+}
+
+// Sets the kind of mouse emulation for the touch input subsystem to perform.
+func SetMouseEmulationMode(mode MouseEmulationMode) {
+	C.al_set_mouse_emulation_mode(C.int(mode))
+}
+
+// Returns the kind of mouse emulation which the touch input subsystem is set
+// to perform.
+func GetMouseEmulationMode() MouseEmulationMode {
+	return MouseEmulationMode(C.al_get_mouse_emulation_mode())
+}
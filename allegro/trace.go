@@ -0,0 +1,299 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+/*
+extern void go_trace_handler(char *msg);
+extern void go_assert_handler(char *expr, char *file, int line, char *func);
+
+static void trace_handler(const char *msg) {
+	go_trace_handler((char *)msg);
+}
+
+static void assert_handler(const char *expr, const char *file, int line, const char *func) {
+	go_assert_handler((char *)expr, (char *)file, line, (char *)func);
+}
+
+static void set_trace_handler(bool on) {
+	al_register_trace_handler(on ? trace_handler : NULL);
+}
+
+static void set_assert_handler(bool on) {
+	al_register_assert_handler(on ? assert_handler : NULL);
+}
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The handlers can be called from any thread, including ones that Allegro
+// starts itself.
+var traceHandlers struct {
+	sync.RWMutex
+	trace  func(string)
+	assert func(expr, file string, line int, fn string)
+}
+
+//export go_trace_handler
+func go_trace_handler(msg *C.char) {
+	traceHandlers.RLock()
+	f := traceHandlers.trace
+	traceHandlers.RUnlock()
+	if f != nil {
+		f(C.GoString(msg))
+	}
+}
+
+//export go_assert_handler
+func go_assert_handler(expr, file *C.char, line C.int, fn *C.char) {
+	traceHandlers.RLock()
+	f := traceHandlers.assert
+	traceHandlers.RUnlock()
+	if f != nil {
+		f(C.GoString(expr), C.GoString(file), int(line), C.GoString(fn))
+	}
+}
+
+// SetTraceHandler() sends Allegro's log messages to f instead of allegro.log.
+// Each message is one line, including the prefix that Allegro puts in front
+// of it and the trailing newline; ParseTrace() splits them up. Which messages
+// are logged is set by the [trace] section of allegro5.cfg, which is read when
+// Allegro starts. Passing nil restores the default.
+func SetTraceHandler(f func(msg string)) {
+	traceHandlers.Lock()
+	traceHandlers.trace = f
+	traceHandlers.Unlock()
+	C.set_trace_handler(C.bool(f != nil))
+}
+
+// SetAssertHandler() calls f instead of aborting when one of Allegro's
+// internal assertions fails. These are only checked by debug builds of
+// Allegro. Allegro carries on after f returns. Passing nil restores the
+// default.
+func SetAssertHandler(f func(expr, file string, line int, fn string)) {
+	traceHandlers.Lock()
+	traceHandlers.assert = f
+	traceHandlers.Unlock()
+	C.set_assert_handler(C.bool(f != nil))
+}
+
+// AssertionError describes an assertion that failed inside Allegro.
+type AssertionError struct {
+	Expr     string
+	File     string
+	Line     int
+	Function string
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("allegro: assertion '%s' failed in %s (%s:%d)", e.Expr, e.Function, e.File, e.Line)
+}
+
+// AssertRecorder collects the assertions that fail while it is the assert
+// handler, so that they can be reported from the test's own goroutine.
+type AssertRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// RecordAsserts() sets an assert handler that records failed assertions as
+// *AssertionErrors, and returns the recorder. Unlike PanicOnAssert(), it
+// works no matter which thread the assertion fails on.
+func RecordAsserts() *AssertRecorder {
+	r := new(AssertRecorder)
+	SetAssertHandler(func(expr, file string, line int, fn string) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.errs = append(r.errs, &AssertionError{Expr: expr, File: file, Line: line, Function: fn})
+	})
+	return r
+}
+
+// Err() returns the assertions that have failed since the last call, joined
+// together, or nil if there were none.
+func (r *AssertRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := errors.Join(r.errs...)
+	r.errs = nil
+	return err
+}
+
+// PanicOnAssert() sets an assert handler that panics with an
+// *AssertionError, which turns failed assertions into test failures with a
+// Go stack trace. The panic unwinds straight through Allegro's C code, which
+// doesn't get to release any locks it holds, and on threads that Allegro
+// started itself it crashes the program, so it is only suitable for tests
+// that call into Allegro from their own goroutine. RecordAsserts() is the
+// safer choice.
+func PanicOnAssert() {
+	SetAssertHandler(func(expr, file string, line int, fn string) {
+		panic(&AssertionError{Expr: expr, File: file, Line: line, Function: fn})
+	})
+}
+
+/* -- Parsing -- */
+
+type TraceLevel int
+
+const (
+	TRACE_DEBUG TraceLevel = iota
+	TRACE_INFO
+	TRACE_WARN
+	TRACE_ERROR
+)
+
+func (l TraceLevel) String() string {
+	switch l {
+	case TRACE_DEBUG:
+		return "debug"
+	case TRACE_INFO:
+		return "info"
+	case TRACE_WARN:
+		return "warn"
+	case TRACE_ERROR:
+		return "error"
+	}
+	return "unknown"
+}
+
+// SlogLevel() returns the matching slog level.
+func (l TraceLevel) SlogLevel() slog.Level {
+	switch l {
+	case TRACE_DEBUG:
+		return slog.LevelDebug
+	case TRACE_WARN:
+		return slog.LevelWarn
+	case TRACE_ERROR:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Trace is a log message from Allegro, split into its parts. File, Line,
+// Function and Time are only filled in if the [trace] section asks for them.
+type Trace struct {
+	Channel  string
+	Level    TraceLevel
+	File     string
+	Line     int
+	Function string
+	Time     float64
+	Message  string
+}
+
+// Allegro prefixes each message with the channel and level, and optionally
+// "file:line", the function and "[time]", all padded with spaces. There is no
+// telling the function apart from the first word of the message unless the
+// time follows it, so it is only picked out then.
+var (
+	traceLevel    = regexp.MustCompile(`^(\S+)\s+([DIWE])\s`)
+	traceFileLine = regexp.MustCompile(`^\s*(\S+):(\d+)\s`)
+	traceFunction = regexp.MustCompile(`^\s*(\S+)\s+\[\s*([0-9.]+)\]\s`)
+	traceTime     = regexp.MustCompile(`^\s*\[\s*([0-9.]+)\]\s`)
+)
+
+// ParseTrace() splits a message passed to a trace handler into its parts, and
+// returns false if it doesn't start with the usual prefix.
+func ParseTrace(msg string) (Trace, bool) {
+	msg = strings.TrimRight(msg, "\r\n")
+	m := traceLevel.FindStringSubmatch(msg)
+	if m == nil {
+		return Trace{Message: msg}, false
+	}
+	t := Trace{Channel: m[1], Level: TraceLevel(strings.IndexByte("DIWE", m[2][0]))}
+	rest := msg[len(m[0]):]
+	if m := traceFileLine.FindStringSubmatch(rest); m != nil {
+		t.File = m[1]
+		t.Line, _ = strconv.Atoi(m[2])
+		rest = rest[len(m[0]):]
+	}
+	if m := traceFunction.FindStringSubmatch(rest); m != nil {
+		t.Function = m[1]
+		t.Time, _ = strconv.ParseFloat(m[2], 64)
+		rest = rest[len(m[0]):]
+	} else if m := traceTime.FindStringSubmatch(rest); m != nil {
+		t.Time, _ = strconv.ParseFloat(m[1], 64)
+		rest = rest[len(m[0]):]
+	}
+	t.Message = strings.TrimLeft(rest, " ")
+	return t, true
+}
+
+// SlogTraceHandler() returns a trace handler that logs Allegro's messages to
+// logger, at the matching level and with the parts of the prefix as
+// attributes. Messages it can't parse are logged as they are, at the info
+// level.
+//
+//	allegro.SetTraceHandler(allegro.SlogTraceHandler(slog.Default()))
+func SlogTraceHandler(logger *slog.Logger) func(string) {
+	return func(msg string) {
+		t, ok := ParseTrace(msg)
+		if !ok {
+			t.Level = TRACE_INFO
+		}
+		ctx := context.Background()
+		level := t.Level.SlogLevel()
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+		r := slog.NewRecord(time.Now(), level, t.Message, 0)
+		if ok {
+			r.AddAttrs(slog.String("channel", t.Channel))
+		}
+		if t.File != "" {
+			r.AddAttrs(slog.String("file", t.File), slog.Int("line", t.Line))
+		}
+		if t.Function != "" {
+			r.AddAttrs(slog.String("function", t.Function))
+		}
+		logger.Handler().Handle(ctx, r)
+	}
+}
+
+// TraceBuffer keeps the most recent messages passed to it, so that they can
+// be included in crash reports.
+type TraceBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// NewTraceBuffer() creates a buffer that holds up to n messages.
+func NewTraceBuffer(n int) *TraceBuffer {
+	return &TraceBuffer{lines: make([]string, n)}
+}
+
+// Add() adds a message to the buffer, dropping the oldest one if it is full.
+// It can be used as a trace handler itself, or called from one.
+func (b *TraceBuffer) Add(msg string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.lines) == 0 {
+		return
+	}
+	b.lines[b.next] = strings.TrimRight(msg, "\r\n")
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Lines() returns the messages in the buffer, oldest first.
+func (b *TraceBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]string(nil), b.lines[:b.next]...)
+	}
+	return append(append([]string(nil), b.lines[b.next:]...), b.lines[:b.next]...)
+}
@@ -0,0 +1,107 @@
+package allegro
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestParseTrace(t *testing.T) {
+	for _, c := range []struct {
+		msg  string
+		want Trace
+	}{
+		{
+			"system   I               system.c:254  al_install_system                [   0.00012] Allegro version: 5.2.9\n",
+			Trace{Channel: "system", Level: TRACE_INFO, File: "system.c", Line: 254,
+				Function: "al_install_system", Time: 0.00012, Message: "Allegro version: 5.2.9"},
+		},
+		{
+			"display  W          xglx_config.c:345  [   1.50000] No matching visual found\n",
+			Trace{Channel: "display", Level: TRACE_WARN, File: "xglx_config.c", Line: 345,
+				Time: 1.5, Message: "No matching visual found"},
+		},
+		{
+			"audio    E Could not open device: busy\n",
+			Trace{Channel: "audio", Level: TRACE_ERROR, Message: "Could not open device: busy"},
+		},
+	} {
+		got, ok := ParseTrace(c.msg)
+		if !ok || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q parsed as %+v, want %+v", c.msg, got, c.want)
+		}
+	}
+	if got, ok := ParseTrace("just some text\n"); ok || got.Message != "just some text" {
+		t.Errorf("unprefixed message parsed as %+v", got)
+	}
+}
+
+type recordHandler struct {
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler            { return h }
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+func TestSlogTraceHandler(t *testing.T) {
+	h := &recordHandler{}
+	handle := SlogTraceHandler(slog.New(h))
+	handle("opengl   W         ogl_display.c:101  [   2.00000] Extension missing\n")
+	handle("not a trace message\n")
+	if len(h.records) != 2 {
+		t.Fatalf("got %d records, want 2", len(h.records))
+	}
+
+	r := h.records[0]
+	attrs := make(map[string]string)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	want := map[string]string{"channel": "opengl", "file": "ogl_display.c", "line": "101"}
+	if r.Level != slog.LevelWarn || r.Message != "Extension missing" || !reflect.DeepEqual(attrs, want) {
+		t.Errorf("record is %v %q %v", r.Level, r.Message, attrs)
+	}
+	if r := h.records[1]; r.Level != slog.LevelInfo || r.Message != "not a trace message" {
+		t.Errorf("unparsed record is %v %q", r.Level, r.Message)
+	}
+}
+
+func TestTraceBuffer(t *testing.T) {
+	b := NewTraceBuffer(3)
+	b.Add("one\n")
+	b.Add("two\n")
+	if got := b.Lines(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("lines are %q", got)
+	}
+	b.Add("three\n")
+	b.Add("four\n")
+	if got := b.Lines(); !reflect.DeepEqual(got, []string{"two", "three", "four"}) {
+		t.Errorf("lines are %q after wrapping", got)
+	}
+}
+
+func TestRecordAsserts(t *testing.T) {
+	r := RecordAsserts()
+	defer SetAssertHandler(nil)
+	if err := r.Err(); err != nil {
+		t.Fatalf("recorded %v before any assertion failed", err)
+	}
+	// Allegro calls the handler through go_assert_handler().
+	traceHandlers.assert("bmp", "bitmap.c", 42, "al_draw_bitmap")
+	err := r.Err()
+	var ae *AssertionError
+	if !errors.As(err, &ae) || ae.Expr != "bmp" || ae.Line != 42 {
+		t.Fatalf("recorded %#v", err)
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("Err() returned %v again", err)
+	}
+}
@@ -14,6 +14,12 @@ func UseTransform(trans *Transform) {
 	C.al_use_transform((*C.ALLEGRO_TRANSFORM)(trans))
 }
 
+// Returns the transformation of the current target bitmap, as set by
+// al_use_transform. If there is no target bitmap, this function returns NULL.
+func CurrentTransform() *Transform {
+	return (*Transform)(C.al_get_current_transform())
+}
+
 // Convenience function for getting a new identity transformation.
 func IdentityTransform() *Transform {
 	var t Transform
@@ -21,26 +27,26 @@ func IdentityTransform() *Transform {
 	return &t
 }
 
-// Builds a transformation given some parameters. This call is equivalent to
-// calling the transformations in this order: make identity, scale, rotate,
-// translate. This method is faster, however, than actually calling those
-// functions.
-func BuildTransform(x, y, sx, sy, theta float32) *Transform {
-	var t Transform
-	C.al_build_transform((*C.ALLEGRO_TRANSFORM)(&t),
-		C.float(x),
-		C.float(y),
-		C.float(sx),
-		C.float(sy),
-		C.float(theta),
-	)
-	return &t
+// Sets the projection transformation to be used for the drawing operations on
+// the target bitmap (each bitmap maintains its own projection transformation).
+// Every drawing operation after this call will be transformed using this
+// transformation. To return default behavior, call this function with an
+// orthographic transform.
+func UseProjectionTransform(trans *Transform) {
+	C.al_use_projection_transform((*C.ALLEGRO_TRANSFORM)(trans))
 }
 
-// Returns the transformation of the current target bitmap, as set by
-// al_use_transform. If there is no target bitmap, this function returns NULL.
-func CurrentTransform() *Transform {
-	return (*Transform)(C.al_get_current_transform())
+// If there is no target bitmap, this function returns NULL. Returns the
+// projection transformation of the current target bitmap, as set by
+// al_use_projection_transform.
+func CurrentProjectionTransform() *Transform {
+	return (*Transform)(C.al_get_current_projection_transform())
+}
+
+// Returns the inverse of the current transformation of the target bitmap. If
+// there is no target bitmap, this function returns NULL.
+func CurrentInverseTransform() *Transform {
+	return (*Transform)(C.al_get_current_inverse_transform())
 }
 
 // Makes a copy of a transformation.
@@ -50,51 +56,134 @@ func (t *Transform) Copy() *Transform {
 	return (*Transform)(&dest)
 }
 
-// Sets the transformation to be the identity transformation. This is the
-// default transformation. Use al_use_transform on an identity transformation
-// to return to the default.
-func (t *Transform) Identity() {
+// Combines the given transformation with a transformation which translates
+// coordinates by the given vector.
+func (t *Transform) Translate3D(x, y, z float32) {
+	C.al_translate_transform_3d((*C.ALLEGRO_TRANSFORM)(t), C.float(x), C.float(y), C.float(z))
+}
+
+// Apply a rotation to a transformation, around the axis given by the vector
+// (x, y, z). The vector should be normalized.
+func (t *Transform) Rotate3D(x, y, z, theta float32) {
+	C.al_rotate_transform_3d((*C.ALLEGRO_TRANSFORM)(t), C.float(x), C.float(y), C.float(z), C.float(theta))
+}
+
+// Apply a scale to a transformation, along all three axes.
+func (t *Transform) Scale3D(sx, sy, sz float32) {
+	C.al_scale_transform_3d((*C.ALLEGRO_TRANSFORM)(t), C.float(sx), C.float(sy), C.float(sz))
+}
+
+// Apply a horizontal shear to the transform.
+func (t *Transform) HorizontalShear(theta float32) {
+	C.al_horizontal_shear_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(theta))
+}
+
+// Apply a vertical shear to the transform.
+func (t *Transform) VerticalShear(theta float32) {
+	C.al_vertical_shear_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(theta))
+}
+
+// Transposes the matrix of the given transform. This can be used for
+// inversing a rotation transform.
+func (t *Transform) Transpose() {
+	C.al_transpose_transform((*C.ALLEGRO_TRANSFORM)(t))
+}
+
+// Transform x, y, z coordinates.
+func (t *Transform) Coordinates3D(x, y, z float32) (float32, float32, float32) {
+	var cx, cy, cz = C.float(x), C.float(y), C.float(z)
+	C.al_transform_coordinates_3d((*C.ALLEGRO_TRANSFORM)(t), &cx, &cy, &cz)
+	return float32(cx), float32(cy), float32(cz)
+}
+
+// Transform x, y, z, w coordinates.
+func (t *Transform) Coordinates4D(x, y, z, w float32) (float32, float32, float32, float32) {
+	var cx, cy, cz, cw = C.float(x), C.float(y), C.float(z), C.float(w)
+	C.al_transform_coordinates_4d((*C.ALLEGRO_TRANSFORM)(t), &cx, &cy, &cz, &cw)
+	return float32(cx), float32(cy), float32(cz), float32(cw)
+}
+
+// Builds a transformation which can be used to transform 3D coordinates in
+// world space to camera space. This involves translation and a rotation. The
+// function expects three coordinate triplets: The camera's position, the
+// position the camera is looking at and an up vector. The up vector does not
+// need to be of unit length and also does not need to be perpendicular to the
+// view direction - it can usually just be the world up direction (most
+// commonly 0/1/0).
+func (t *Transform) BuildCamera(posX, posY, posZ, lookX, lookY, lookZ, upX, upY, upZ float32) {
+	C.al_build_camera_transform((*C.ALLEGRO_TRANSFORM)(t),
+		C.float(posX), C.float(posY), C.float(posZ),
+		C.float(lookX), C.float(lookY), C.float(lookZ),
+		C.float(upX), C.float(upY), C.float(upZ),
+	)
+}
+
+// Combines the given transformation with an orthographic transformation which
+// maps the screen rectangle to the given left/top and right/bottom
+// coordinates. near/far is the z range, coordinates outside of that range will
+// get clipped.
+func (t *Transform) Orthographic(left, top, near, right, bottom, far float32) {
+	C.al_orthographic_transform((*C.ALLEGRO_TRANSFORM)(t),
+		C.float(left), C.float(top), C.float(near),
+		C.float(right), C.float(bottom), C.float(far),
+	)
+}
+
+// Like al_orthographic_transform but honors perspective. If everything is at
+// a z-position of -near it will look the same as with an orthographic
+// transformation.
+func (t *Transform) Perspective(left, top, near, right, bottom, far float32) {
+	C.al_perspective_transform((*C.ALLEGRO_TRANSFORM)(t),
+		C.float(left), C.float(top), C.float(near),
+		C.float(right), C.float(bottom), C.float(far),
+	)
+}
+
+// Matrix() returns a copy of the transformation's 4x4 matrix, indexed as
+// m[column][row] like Allegro's own ALLEGRO_TRANSFORM. This is mostly useful
+// for inspecting or testing transformations without a display.
+func (t *Transform) Matrix() [4][4]float32 {
+	return *t.mat()
+}
+
+// The functions below call straight into Allegro's own implementations of the
+// transformations that transform_math.go reimplements in Go. They are only
+// kept around so that the two can be checked against each other.
+
+func cBuildTransform(t *Transform, x, y, sx, sy, theta float32) {
+	C.al_build_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(x), C.float(y), C.float(sx), C.float(sy), C.float(theta))
+}
+
+func cIdentityTransform(t *Transform) {
 	C.al_identity_transform((*C.ALLEGRO_TRANSFORM)(t))
 }
 
-// Apply a translation to a transformation.
-func (t *Transform) Translate(x, y float32) {
+func cTranslateTransform(t *Transform, x, y float32) {
 	C.al_translate_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(x), C.float(y))
 }
 
-// Apply a rotation to a transformation.
-func (t *Transform) Rotate(theta float32) {
+func cRotateTransform(t *Transform, theta float32) {
 	C.al_rotate_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(theta))
 }
 
-// Apply a scale to a transformation.
-func (t *Transform) Scale(sx, sy float32) {
+func cScaleTransform(t *Transform, sx, sy float32) {
 	C.al_scale_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(sx), C.float(sy))
 }
 
-// Transform a pair of coordinates.
-func (t *Transform) Coordinates(x, y float32) (float32, float32) {
+func cTransformCoordinates(t *Transform, x, y float32) (float32, float32) {
 	var cx, cy = C.float(x), C.float(y)
 	C.al_transform_coordinates((*C.ALLEGRO_TRANSFORM)(t), &cx, &cy)
-	return float32(x), float32(y)
+	return float32(cx), float32(cy)
 }
 
-// Compose (combine) two transformations by a matrix multiplication.
-func (t *Transform) Compose(other *Transform) {
+func cComposeTransform(t, other *Transform) {
 	C.al_compose_transform((*C.ALLEGRO_TRANSFORM)(t), (*C.ALLEGRO_TRANSFORM)(other))
 }
 
-// Inverts the passed transformation. If the transformation is nearly singular
-// (close to not having an inverse) then the returned transformation may be
-// invalid. Use al_check_inverse to ascertain if the transformation has an
-// inverse before inverting it if you are in doubt.
-func (t *Transform) Invert() {
+func cInvertTransform(t *Transform) {
 	C.al_invert_transform((*C.ALLEGRO_TRANSFORM)(t))
 }
 
-// Checks if the transformation has an inverse using the supplied tolerance.
-// Tolerance should be a small value between 0 and 1, with 1e-7 being
-// sufficient for most applications.
-func (t *Transform) CheckInverse(tol float32) bool {
+func cCheckInverse(t *Transform, tol float32) bool {
 	return int(C.al_check_inverse((*C.ALLEGRO_TRANSFORM)(t), C.float(tol))) != 0
 }
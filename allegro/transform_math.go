@@ -0,0 +1,163 @@
+package allegro
+
+import (
+	"math"
+	"unsafe"
+)
+
+// This file contains pure Go versions of Allegro's 2D transformation
+// functions. They operate directly on the ALLEGRO_TRANSFORM memory, and
+// follow Allegro's own arithmetic step by step so that the results match,
+// but without paying for a cgo call each time. That adds up quickly when
+// building a transformation for every sprite in every frame.
+//
+// Every product is wrapped in an explicit float32() conversion, which stops
+// the compiler from fusing multiply-adds and keeps the rounding the same as
+// in C.
+
+// A Point is a pair of coordinates that can be transformed in bulk.
+type Point struct {
+	X float32
+	Y float32
+}
+
+func (t *Transform) mat() *[4][4]float32 {
+	return (*[4][4]float32)(unsafe.Pointer(t))
+}
+
+func sincos(theta float32) (float32, float32) {
+	s, c := math.Sincos(float64(theta))
+	return float32(s), float32(c)
+}
+
+// Builds a transformation given some parameters. This call is equivalent to
+// calling the transformations in this order: make identity, scale, rotate,
+// translate. This method is faster, however, than actually calling those
+// functions.
+func BuildTransform(x, y, sx, sy, theta float32) *Transform {
+	var t Transform
+	s, c := sincos(theta)
+	m := t.mat()
+	m[0][0] = float32(sx * c)
+	m[0][1] = float32(sx * s)
+	m[1][0] = float32(-sy * s)
+	m[1][1] = float32(sy * c)
+	m[2][2] = 1
+	m[3][0] = x
+	m[3][1] = y
+	m[3][3] = 1
+	return &t
+}
+
+// Sets the transformation to be the identity transformation. This is the
+// default transformation. Use al_use_transform on an identity transformation
+// to return to the default.
+func (t *Transform) Identity() {
+	*t.mat() = [4][4]float32{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Apply a translation to a transformation.
+func (t *Transform) Translate(x, y float32) {
+	m := t.mat()
+	m[3][0] += x
+	m[3][1] += y
+}
+
+// Apply a rotation to a transformation.
+func (t *Transform) Rotate(theta float32) {
+	s, c := sincos(theta)
+	m := t.mat()
+	for _, i := range [...]int{0, 1, 3} {
+		x, y := m[i][0], m[i][1]
+		m[i][0] = float32(x*c) - float32(y*s)
+		m[i][1] = float32(x*s) + float32(y*c)
+	}
+}
+
+// Apply a scale to a transformation.
+func (t *Transform) Scale(sx, sy float32) {
+	m := t.mat()
+	for _, i := range [...]int{0, 1, 3} {
+		m[i][0] *= sx
+		m[i][1] *= sy
+	}
+}
+
+// Transform a pair of coordinates.
+func (t *Transform) Coordinates(x, y float32) (float32, float32) {
+	m := t.mat()
+	return float32(x*m[0][0]) + float32(y*m[1][0]) + m[3][0],
+		float32(x*m[0][1]) + float32(y*m[1][1]) + m[3][1]
+}
+
+// TransformPoints() transforms every point in the slice in place. It does the
+// same as calling Coordinates() on each of them, in a single call.
+func (t *Transform) TransformPoints(points []Point) {
+	m := t.mat()
+	for i, p := range points {
+		points[i].X = float32(p.X*m[0][0]) + float32(p.Y*m[1][0]) + m[3][0]
+		points[i].Y = float32(p.X*m[0][1]) + float32(p.Y*m[1][1]) + m[3][1]
+	}
+}
+
+// Compose (combine) two transformations by a matrix multiplication.
+func (t *Transform) Compose(other *Transform) {
+	m, o := t.mat(), other.mat()
+	var tmp [4][4]float32
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			tmp[x][y] = float32(o[0][y]*m[x][0]) + float32(o[1][y]*m[x][1]) +
+				float32(o[2][y]*m[x][2]) + float32(o[3][y]*m[x][3])
+		}
+	}
+	*m = tmp
+}
+
+// Inverts the passed transformation. If the transformation is nearly singular
+// (close to not having an inverse) then the returned transformation may be
+// invalid. Use al_check_inverse to ascertain if the transformation has an
+// inverse before inverting it if you are in doubt.
+func (t *Transform) Invert() {
+	m := t.mat()
+	det := float32(m[0][0]*m[1][1]) - float32(m[1][0]*m[0][1])
+
+	x := m[3][0]
+	m[3][0] = (float32(m[1][0]*m[3][1]) - float32(x*m[1][1])) / det
+	m[3][1] = (float32(x*m[0][1]) - float32(m[0][0]*m[3][1])) / det
+
+	x = m[0][0]
+	m[0][0] = m[1][1] / det
+	m[1][1] = x / det
+	m[0][1] = -m[0][1] / det
+	m[1][0] = -m[1][0] / det
+}
+
+// Checks if the transformation has an inverse using the supplied tolerance.
+// Tolerance should be a small value between 0 and 1, with 1e-7 being
+// sufficient for most applications.
+func (t *Transform) CheckInverse(tol float32) bool {
+	m := t.mat()
+	det := abs32(float32(m[0][0]*m[1][1]) - float32(m[1][0]*m[0][1]))
+	// Like Allegro, this uses the 1-norm of the columns, including the
+	// translation, and never lets it drop below 1.
+	norm := float32(1)
+	for _, c := range [...]float32{
+		abs32(m[0][0]) + abs32(m[0][1]),
+		abs32(m[1][0]) + abs32(m[1][1]),
+		abs32(m[3][0]) + abs32(m[3][1]) + 1,
+	} {
+		if c > norm {
+			norm = c
+		}
+	}
+	return det > float32(tol*norm)
+}
+
+func abs32(f float32) float32 {
+	return math.Float32frombits(math.Float32bits(f) &^ (1 << 31))
+}
@@ -0,0 +1,178 @@
+package allegro
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestTranslate3DMatrix(t *testing.T) {
+	trans := IdentityTransform()
+	trans.Translate3D(1, 2, 3)
+	m := trans.Matrix()
+	if m[3][0] != 1 || m[3][1] != 2 || m[3][2] != 3 || m[3][3] != 1 {
+		t.Errorf("unexpected translation column: %v", m[3])
+	}
+}
+
+func TestOrthographic(t *testing.T) {
+	trans := IdentityTransform()
+	trans.Orthographic(0, 0, -1, 640, 480, 1)
+	for _, c := range []struct{ x, y, wantX, wantY float32 }{
+		{0, 0, -1, 1},
+		{640, 480, 1, -1},
+		{320, 240, 0, 0},
+	} {
+		x, y, _ := trans.Coordinates3D(c.x, c.y, 0)
+		if !near(x, c.wantX) || !near(y, c.wantY) {
+			t.Errorf("(%v, %v) => (%v, %v), want (%v, %v)", c.x, c.y, x, y, c.wantX, c.wantY)
+		}
+	}
+}
+
+func TestPerspective(t *testing.T) {
+	trans := IdentityTransform()
+	trans.Perspective(-1, 1, 1, 1, -1, 100)
+	x, y, z, w := trans.Coordinates4D(1, 1, -1, 1)
+	if !near(x/w, 1) || !near(y/w, 1) || !near(z/w, -1) {
+		t.Errorf("near plane corner => (%v, %v, %v), want (1, 1, -1)", x/w, y/w, z/w)
+	}
+}
+
+// ulps returns the distance between a and b in units in the last place.
+func ulps(a, b float32) int64 {
+	ia, ib := int64(math.Float32bits(a)), int64(math.Float32bits(b))
+	if ia&(1<<31) != 0 {
+		ia = (1 << 31) - ia
+	}
+	if ib&(1<<31) != 0 {
+		ib = (1 << 31) - ib
+	}
+	if d := ia - ib; d >= 0 {
+		return d
+	} else {
+		return -d
+	}
+}
+
+// close32 allows a few ULPs of difference, since Go's sin/cos don't always
+// round the same way as the C library's sinf/cosf.
+func close32(a, b float32) bool {
+	return ulps(a, b) <= 4 || math.Abs(float64(a-b)) < 1e-6
+}
+
+func checkParity(t *testing.T, name string, got, want *Transform) {
+	g, w := got.Matrix(), want.Matrix()
+	for i := range g {
+		for j := range g[i] {
+			if !close32(g[i][j], w[i][j]) {
+				t.Errorf("%s: m[%d][%d] = %v, Allegro has %v", name, i, j, g[i][j], w[i][j])
+			}
+		}
+	}
+}
+
+var parityCases = []struct {
+	name                string
+	x, y, sx, sy, theta float32
+}{
+	{"identity", 0, 0, 1, 1, 0},
+	{"translate", 12.5, -3.25, 1, 1, 0},
+	{"scale", 0, 0, 2, 0.5, 0},
+	{"rotate", 0, 0, 1, 1, math.Pi / 3},
+	{"negative rotate", 0, 0, 1, 1, -2.1},
+	{"sprite", 320, 240, 1.5, 1.5, 0.7},
+	{"mirrored", -64, 17, -1, 3, 4.2},
+}
+
+func TestTransformParity(t *testing.T) {
+	for _, c := range parityCases {
+		var got, want Transform
+
+		got = *BuildTransform(c.x, c.y, c.sx, c.sy, c.theta)
+		cBuildTransform(&want, c.x, c.y, c.sx, c.sy, c.theta)
+		checkParity(t, c.name+"/build", &got, &want)
+
+		got.Identity()
+		cIdentityTransform(&want)
+		checkParity(t, c.name+"/identity", &got, &want)
+
+		got.Scale(c.sx, c.sy)
+		cScaleTransform(&want, c.sx, c.sy)
+		got.Rotate(c.theta)
+		cRotateTransform(&want, c.theta)
+		got.Translate(c.x, c.y)
+		cTranslateTransform(&want, c.x, c.y)
+		checkParity(t, c.name+"/steps", &got, &want)
+
+		gx, gy := got.Coordinates(3, -7)
+		wx, wy := cTransformCoordinates(&want, 3, -7)
+		if !close32(gx, wx) || !close32(gy, wy) {
+			t.Errorf("%s/coordinates: (%v, %v), Allegro has (%v, %v)", c.name, gx, gy, wx, wy)
+		}
+
+		other := BuildTransform(5, 6, 0.25, 4, 1)
+		got.Compose(other)
+		cComposeTransform(&want, other)
+		checkParity(t, c.name+"/compose", &got, &want)
+
+		if g, w := got.CheckInverse(1e-7), cCheckInverse(&want, 1e-7); g != w {
+			t.Errorf("%s/check inverse: %v, Allegro has %v", c.name, g, w)
+		}
+		got.Invert()
+		cInvertTransform(&want)
+		checkParity(t, c.name+"/invert", &got, &want)
+	}
+}
+
+func TestCheckInverseSingular(t *testing.T) {
+	trans := IdentityTransform()
+	trans.Scale(0, 1)
+	if trans.CheckInverse(1e-7) {
+		t.Error("singular transformation reported as invertible")
+	}
+}
+
+func TestCheckInverseParity(t *testing.T) {
+	for _, c := range []struct {
+		name                string
+		x, y, sx, sy, theta float32
+		tol                 float32
+		want                bool
+	}{
+		{"identity", 0, 0, 1, 1, 0, 1e-7, true},
+		{"tiny scale", 0, 0, 1e-4, 1e-4, 0, 1e-7, false},
+		{"small scale", 0, 0, 1e-3, 1e-3, 0, 1e-7, true},
+		{"tiny rotated scale", 5, 5, 1e-4, 2e-4, 0.3, 1e-7, false},
+		{"large translation", 1e6, -1e6, 0.5, 0.5, 0, 1e-7, true},
+		{"huge translation", 1e7, -1e7, 0.5, 0.5, 0, 1e-7, false},
+		{"loose tolerance", 0, 0, 0.5, 0.5, 0, 0.5, false},
+	} {
+		trans := BuildTransform(c.x, c.y, c.sx, c.sy, c.theta)
+		got, allegro := trans.CheckInverse(c.tol), cCheckInverse(trans, c.tol)
+		if got != c.want {
+			t.Errorf("%s: %v, want %v", c.name, got, c.want)
+		}
+		if got != allegro {
+			t.Errorf("%s: %v, Allegro has %v", c.name, got, allegro)
+		}
+	}
+}
+
+func TestTransformPoints(t *testing.T) {
+	trans := BuildTransform(10, 20, 2, 3, 0.5)
+	points := []Point{{0, 0}, {1, 0}, {0, 1}, {-4.5, 8}}
+	want := make([]Point, len(points))
+	for i, p := range points {
+		want[i].X, want[i].Y = trans.Coordinates(p.X, p.Y)
+	}
+	trans.TransformPoints(points)
+	for i := range points {
+		if points[i] != want[i] {
+			t.Errorf("point %d: %v, want %v", i, points[i], want[i])
+		}
+	}
+}
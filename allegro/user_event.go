@@ -0,0 +1,138 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+// #include <stdint.h>
+// #include <stdlib.h>
+/*
+enum { NAG_VALUE_EVENT = ALLEGRO_GET_EVENT_TYPE('N', 'A', 'G', 'V') };
+
+extern void go_release_user_event(uintptr_t handle);
+
+static void release_user_event(ALLEGRO_USER_EVENT *event) {
+	go_release_user_event((uintptr_t)event->data1);
+}
+
+static ALLEGRO_EVENT_SOURCE *create_user_event_source(void) {
+	ALLEGRO_EVENT_SOURCE *src = calloc(1, sizeof(ALLEGRO_EVENT_SOURCE));
+	al_init_user_event_source(src);
+	return src;
+}
+
+static void destroy_user_event_source(ALLEGRO_EVENT_SOURCE *src) {
+	al_destroy_user_event_source(src);
+	free(src);
+}
+
+static bool emit_value_event(ALLEGRO_EVENT_SOURCE *src, uintptr_t handle) {
+	ALLEGRO_EVENT ev = {0};
+	ev.user.type = NAG_VALUE_EVENT;
+	ev.user.data1 = (intptr_t)handle;
+	return al_emit_user_event(src, &ev, release_user_event);
+}
+*/
+import "C"
+import (
+	"errors"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// UserEventSource is an event source for carrying arbitrary Go values into
+// event queues. Unlike an EventSource initialised with
+// InitUserEventSource(), its memory is owned by Allegro, so it is safe to
+// pass around and emit from any goroutine.
+type UserEventSource C.ALLEGRO_EVENT_SOURCE
+
+//export go_release_user_event
+func go_release_user_event(handle C.uintptr_t) {
+	cgo.Handle(handle).Delete()
+}
+
+// NewUserEventSource() creates and initialises a new user event source.
+func NewUserEventSource() *UserEventSource {
+	return (*UserEventSource)(C.create_user_event_source())
+}
+
+// Retrieve the associated event source.
+func (s *UserEventSource) EventSource() *EventSource {
+	return (*EventSource)(s)
+}
+
+// Emit() sends v to every event queue the source is registered with, where
+// it comes out as a UserEvent whose Value() returns v. The event is reference
+// counted, so each queue's consumer must call Unref() on it once done; v is
+// released once the last of them has done so. An error is returned if the
+// source isn't registered with any queues.
+func (s *UserEventSource) Emit(v interface{}) error {
+	h := cgo.NewHandle(v)
+	// If the event can't be delivered, Allegro calls the destructor right
+	// away, which takes care of the handle.
+	if !bool(C.emit_value_event((*C.ALLEGRO_EVENT_SOURCE)(s), C.uintptr_t(h))) {
+		return errors.New("failed to emit user event; source isn't registered with any queues")
+	}
+	return nil
+}
+
+// emitted_event is the value carried by the events sent with EmitEvent().
+type emitted_event Event
+
+// EmitEvent() sends a copy of ev to every event queue the source is
+// registered with. Unlike with Emit(), the event comes out of the queue as
+// what it is, e.g. a KeyDownEvent filled in with Event.SetKeyDown(), with its
+// timestamp and pointers intact, so it can't be told apart from the real
+// thing. An error is returned if the source isn't registered with any queues.
+func (s *UserEventSource) EmitEvent(ev *Event) error {
+	c := emitted_event(*ev)
+	return s.Emit(&c)
+}
+
+// unwrap() replaces an event sent with EmitEvent() with the event it carries.
+// The user event is released unless it was only peeked at, and is still in
+// the queue.
+func (e *Event) unwrap(release bool) {
+	u := (*user_event)(unsafe.Pointer(e))
+	if u._type != C.NAG_VALUE_EVENT {
+		return
+	}
+	c, ok := u.Value().(*emitted_event)
+	if !ok {
+		return
+	}
+	if release {
+		u.Unref()
+	}
+	*e = Event(*c)
+}
+
+// Destroy an event source created with NewUserEventSource().
+func (s *UserEventSource) Destroy() {
+	s.EventSource().SetData(nil)
+	C.destroy_user_event_source((*C.ALLEGRO_EVENT_SOURCE)(s))
+}
+
+// cloned_user_event is a clone of a user event, with the value it carried.
+type cloned_user_event struct {
+	user_event
+	value interface{}
+}
+
+func (e *cloned_user_event) Clone() UserEvent {
+	c := *e
+	return &c
+}
+
+func (e *cloned_user_event) Value() interface{} {
+	return e.value
+}
+
+func (e *cloned_user_event) Unref() {}
+
+// Value() returns the Go value carried by an event emitted with
+// UserEventSource.Emit(), or nil for any other user event. It must be called
+// before the event is unreferenced.
+func (e *user_event) Value() interface{} {
+	if e._type != C.NAG_VALUE_EVENT {
+		return nil
+	}
+	return cgo.Handle(e.data1).Value()
+}
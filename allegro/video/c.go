@@ -0,0 +1,4 @@
+package video
+
+// #cgo !windows pkg-config: allegro_video-5
+import "C"
@@ -0,0 +1,273 @@
+// Package video provides support for Allegro's video addon, which plays Ogg
+// Theora videos.
+//
+// A video is played by starting it, with its audio going to a mixer, and then
+// drawing its current frame whenever a FrameShowEvent arrives:
+//
+//	v, err := video.Open("intro.ogv")
+//	if err != nil {
+//		return err
+//	}
+//	defer v.Close()
+//	queue.Register(v)
+//	v.Start(mixer)
+//	for {
+//		switch queue.WaitForEvent(&event).(type) {
+//		case video.FrameShowEvent:
+//			v.DrawFrame(0, 0, float32(display.Width()), float32(display.Height()))
+//			allegro.FlipDisplay()
+//		case video.FinishedEvent:
+//			return nil
+//		}
+//	}
+package video
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_audio.h>
+// #include <allegro5/allegro_video.h>
+// #include "../util.c"
+/*
+static ALLEGRO_VIDEO *_video_of(ALLEGRO_USER_EVENT *e) {
+	return (ALLEGRO_VIDEO *)e->data1;
+}
+
+// al_is_video_addon_initialized() only exists from Allegro 5.2.6 on.
+static bool video_initialized(void) {
+#if ALLEGRO_VERSION_INT >= AL_ID(5, 2, 6, 0)
+	return al_is_video_addon_initialized();
+#else
+	return true;
+#endif
+}
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/phrasz/nag/allegro"
+	"github.com/phrasz/nag/allegro/audio"
+)
+
+func init() {
+	allegro.RegisterEventType(C.ALLEGRO_EVENT_VIDEO_FRAME_SHOW, func(e *allegro.Event) interface{} {
+		return (*frame_show_event)(unsafe.Pointer(e))
+	})
+	allegro.RegisterEventType(C.ALLEGRO_EVENT_VIDEO_FINISHED, func(e *allegro.Event) interface{} {
+		return (*finished_event)(unsafe.Pointer(e))
+	})
+}
+
+type Video C.ALLEGRO_VIDEO
+
+type PositionType C.ALLEGRO_VIDEO_POSITION_TYPE
+
+const (
+	POSITION_ACTUAL       PositionType = C.ALLEGRO_VIDEO_POSITION_ACTUAL
+	POSITION_VIDEO_DECODE              = C.ALLEGRO_VIDEO_POSITION_VIDEO_DECODE
+	POSITION_AUDIO_DECODE              = C.ALLEGRO_VIDEO_POSITION_AUDIO_DECODE
+)
+
+// Initializes the video addon.
+func Install() error {
+	if !bool(C.al_init_video_addon()) {
+		return &allegro.Error{Op: "install", Addon: "video"}
+	}
+	return nil
+}
+
+// Shut down the video addon. This is done automatically at program exit, but
+// can be called any time the user wishes as well.
+func Uninstall() {
+	C.al_shutdown_video_addon()
+}
+
+// Returns the (compiled) version of the addon, in the same format as
+// al_get_allegro_version.
+func Version() (major, minor, revision, release uint8) {
+	v := uint32(C.al_get_allegro_video_version())
+	major = uint8(v >> 24)
+	minor = uint8((v >> 16) & 255)
+	revision = uint8((v >> 8) & 255)
+	release = uint8(v & 255)
+	return
+}
+
+// installed() returns true if the addon has been initialized. Allegro older
+// than 5.2.6 can't tell, so it's assumed to be.
+func installed() bool {
+	return bool(C.video_initialized())
+}
+
+// Reads a video file. This does not start streaming yet but reads the meta
+// info so you can query e.g. the size or audio rate.
+func Open(filename string) (*Video, error) {
+	filename_ := C.CString(filename)
+	defer C.free_string(filename_)
+	if !installed() {
+		return nil, &allegro.Error{Op: "open video", Path: filename, Addon: "video", Err: allegro.ErrNotInstalled}
+	}
+	v := C.al_open_video(filename_)
+	if v == nil {
+		return nil, allegro.LoadError("video", "open video", filename)
+	}
+	return (*Video)(v), nil
+}
+
+// Closes the video and frees all allocated resources. The video pointer is
+// invalid after the function returns.
+func (v *Video) Close() {
+	C.al_close_video((*C.ALLEGRO_VIDEO)(v))
+}
+
+// Starts streaming the video from the beginning, with its audio going to the
+// given mixer, or to the default mixer if it is nil.
+func (v *Video) Start(mixer *audio.Mixer) {
+	if mixer == nil {
+		mixer = audio.DefaultMixer()
+	}
+	C.al_start_video((*C.ALLEGRO_VIDEO)(v), (*C.ALLEGRO_MIXER)(unsafe.Pointer(mixer)))
+}
+
+// Like Start(), but the audio is routed directly to a voice.
+func (v *Video) StartWithVoice(voice *audio.Voice) {
+	C.al_start_video_with_voice((*C.ALLEGRO_VIDEO)(v), (*C.ALLEGRO_VOICE)(unsafe.Pointer(voice)))
+}
+
+// Get an event source for the video. The possible events are FrameShowEvent
+// and FinishedEvent.
+func (v *Video) EventSource() *allegro.EventSource {
+	return (*allegro.EventSource)(unsafe.Pointer(C.al_get_video_event_source((*C.ALLEGRO_VIDEO)(v))))
+}
+
+// Pauses or resumes playback.
+func (v *Video) SetPlaying(playing bool) {
+	C.al_set_video_playing((*C.ALLEGRO_VIDEO)(v), C.bool(playing))
+}
+
+// Returns true if the video is currently playing.
+func (v *Video) IsPlaying() bool {
+	return bool(C.al_is_video_playing((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the audio rate of the video, in Hz.
+func (v *Video) AudioRate() float64 {
+	return float64(C.al_get_video_audio_rate((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the speed of the video in frames per second. Often this will not be
+// an integer value.
+func (v *Video) FPS() float64 {
+	return float64(C.al_get_video_fps((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the width with which the video frame should be drawn. Videos often
+// do not use square pixels, so this may be different from the width of the
+// frame bitmap.
+func (v *Video) ScaledWidth() float32 {
+	return float32(C.al_get_video_scaled_width((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the height with which the video frame should be drawn.
+func (v *Video) ScaledHeight() float32 {
+	return float32(C.al_get_video_scaled_height((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the current video frame, or nil if no frame is available. The
+// bitmap belongs to the video and is updated as it plays, so it shouldn't be
+// destroyed or kept around.
+func (v *Video) Frame() *allegro.Bitmap {
+	return (*allegro.Bitmap)(unsafe.Pointer(C.al_get_video_frame((*C.ALLEGRO_VIDEO)(v))))
+}
+
+// Returns the current position of the video stream in seconds since the
+// beginning.
+func (v *Video) Position(which PositionType) float64 {
+	return float64(C.al_get_video_position((*C.ALLEGRO_VIDEO)(v), C.ALLEGRO_VIDEO_POSITION_TYPE(which)))
+}
+
+// Seek to a different position in the video. Currently only seeking to the
+// beginning of the video is supported.
+func (v *Video) Seek(seconds float64) error {
+	if !bool(C.al_seek_video((*C.ALLEGRO_VIDEO)(v), C.double(seconds))) {
+		return fmt.Errorf("failed to seek video to %gs", seconds)
+	}
+	return nil
+}
+
+// DrawFrame() draws the current frame into the given rectangle, as large as
+// it fits while keeping the video's aspect ratio, and centred. It returns
+// false if there is no frame to draw yet.
+func (v *Video) DrawFrame(x, y, w, h float32) bool {
+	frame := v.Frame()
+	if frame == nil {
+		return false
+	}
+	sw, sh := v.ScaledWidth(), v.ScaledHeight()
+	if sw <= 0 || sh <= 0 {
+		return false
+	}
+	scale := w / sw
+	if h/sh < scale {
+		scale = h / sh
+	}
+	dw, dh := sw*scale, sh*scale
+	frame.DrawScaled(0, 0, float32(frame.Width()), float32(frame.Height()),
+		x+(w-dw)/2, y+(h-dh)/2, dw, dh, 0)
+	return true
+}
+
+/* -- Frame Show -- */
+
+// FrameShowEvent is generated when it is time to show a new frame. Once it is
+// received, the frame can be drawn with Frame() or DrawFrame().
+type FrameShowEvent interface {
+	frame_show()
+	Clone() FrameShowEvent
+	Timestamp() float64
+	Video() *Video
+}
+
+type frame_show_event C.struct_ALLEGRO_USER_EVENT
+
+func (e *frame_show_event) frame_show() {}
+
+func (e *frame_show_event) Clone() FrameShowEvent {
+	c := *e
+	return &c
+}
+
+func (e *frame_show_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *frame_show_event) Video() *Video {
+	return (*Video)(C._video_of((*C.ALLEGRO_USER_EVENT)(e)))
+}
+
+/* -- Finished -- */
+
+// FinishedEvent is generated when the video has finished playing.
+type FinishedEvent interface {
+	finished()
+	Clone() FinishedEvent
+	Timestamp() float64
+	Video() *Video
+}
+
+type finished_event C.struct_ALLEGRO_USER_EVENT
+
+func (e *finished_event) finished() {}
+
+func (e *finished_event) Clone() FinishedEvent {
+	c := *e
+	return &c
+}
+
+func (e *finished_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *finished_event) Video() *Video {
+	return (*Video)(C._video_of((*C.ALLEGRO_USER_EVENT)(e)))
+}